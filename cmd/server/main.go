@@ -14,7 +14,11 @@ import (
 	"github.com/podushkina/taskqueue/internal/api"
 	"github.com/podushkina/taskqueue/internal/config"
 	"github.com/podushkina/taskqueue/internal/metrics"
+	"github.com/podushkina/taskqueue/internal/model"
 	"github.com/podushkina/taskqueue/internal/repository"
+	"github.com/podushkina/taskqueue/internal/scheduler"
+	"github.com/podushkina/taskqueue/internal/schema"
+	"github.com/podushkina/taskqueue/internal/webhook"
 	"github.com/podushkina/taskqueue/internal/worker"
 	"github.com/podushkina/taskqueue/migrations"
 )
@@ -53,7 +57,16 @@ func main() {
 
 	postgresRepo := repository.NewPostgresRepository(db)
 
-	redisQueue, err := repository.NewRedisQueue(cfg.RedisAddr, cfg.RedisPass, cfg.RedisDB)
+	priorityWeights := make(map[model.Priority]int, len(cfg.PriorityWeights))
+	for name, w := range cfg.PriorityWeights {
+		priorityWeights[model.Priority(name)] = w
+	}
+
+	redisQueue, err := repository.NewRedisQueue(cfg.RedisAddr, cfg.RedisPass, cfg.RedisDB,
+		repository.WithVisibilityTimeout(cfg.VisibilityTimeout),
+		repository.WithPriorityWeights(priorityWeights),
+		repository.WithIdempotencyWindow(cfg.IdempotencyWindow),
+	)
 	if err != nil {
 		logger.Error("Failed to connect to Redis", "error", err)
 		_ = db.Close()
@@ -61,25 +74,106 @@ func main() {
 	}
 	logger.Info("Connected to Redis successfully")
 
+	if moved, err := redisQueue.MigrateLegacyPending(context.Background()); err != nil {
+		logger.Error("Failed to migrate legacy pending queues", "error", err)
+	} else if moved > 0 {
+		logger.Info("Migrated legacy pending tasks to named queues", "count", moved)
+	}
+
 	m := metrics.NewMetrics()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	redisQueue.StartQueueDepthCollector(ctx, m, 2*time.Second)
+	redisQueue.StartReaper(ctx, m, postgresRepo, 5*time.Second)
+	redisQueue.StartPromoter(ctx, 1*time.Second)
 
-	pool := worker.NewPool(redisQueue, postgresRepo, m, cfg.WorkerCount)
+	queues := make([]worker.Queue, 0, len(cfg.Queues))
+	for _, q := range cfg.Queues {
+		if !model.ValidQueueName(q.Name) {
+			logger.Error("Invalid queue name in QUEUES", "queue", q.Name)
+			os.Exit(1)
+		}
+		queues = append(queues, worker.Queue{Name: q.Name, Weight: q.Weight})
+	}
+	queueOption := worker.WithQueues(queues...)
+	if cfg.StrictQueues {
+		names := make([]string, len(queues))
+		for i, q := range queues {
+			names[i] = q.Name
+		}
+		queueOption = worker.WithStrictQueues(names...)
+	}
 
-	pool.Register("echo", worker.Echo)
-	pool.Register("reverse", worker.Reverse)
-	pool.Register("sum", worker.Sum)
-	pool.Register("slow", worker.Slow)
-	pool.Register("flaky", worker.Flaky)
+	callbacks := webhook.NewDispatcher(redisQueue,
+		webhook.WithMaxAttempts(cfg.CallbackMaxAttempts),
+		webhook.WithTimeout(cfg.CallbackTimeout),
+		webhook.WithAllowedNetworks(cfg.CallbackAllowedNetworks...))
+	callbacks.Start(1 * time.Second)
+
+	pool := worker.NewPool(redisQueue, postgresRepo, m, cfg.WorkerCount, queueOption,
+		worker.WithMaxRetryDelay(cfg.MaxBackoffDelay))
+
+	pool.Register("echo", worker.Echo,
+		worker.WithDescription("Возвращает переданный payload"))
+	worker.RegisterTyped(pool, "reverse", worker.Reverse,
+		worker.WithDescription("Переворачивает строку"), worker.WithSchema(worker.ReverseSchema))
+	worker.RegisterTyped(pool, "sum", worker.Sum,
+		worker.WithDescription("Суммирует массив чисел"), worker.WithSchema(worker.SumSchema))
+	pool.Register("slow", worker.Slow,
+		worker.WithDescription("Имитация длительной операции (5 сек)"),
+		worker.WithTimeout(time.Minute), worker.WithConcurrency(1))
+	pool.Register("flaky", worker.Flaky,
+		worker.WithDescription("Имитация нестабильной работы для тестов"),
+		worker.WithMaxRetry(5), worker.WithBackoff(model.Backoff{
+			Strategy: model.BackoffExponential,
+			Delay:    model.Duration(500 * time.Millisecond),
+			MaxDelay: model.Duration(10 * time.Second),
+			Jitter:   0.2,
+		}))
+
+	// Известны все типы пула со схемами из Register; дополнительные схемы
+	// можно добавить файлами из SCHEMA_DIR
+	schemas := schema.NewRegistry()
+	for _, tt := range pool.TaskTypes() {
+		if err := schemas.Register(tt.Name, tt.Schema); err != nil {
+			logger.Error("Failed to register payload schema", "error", err)
+			os.Exit(1)
+		}
+	}
+	if cfg.SchemaDir != "" {
+		if err := schemas.LoadDir(cfg.SchemaDir); err != nil {
+			logger.Error("Failed to load payload schemas", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	pool.Start(ctx)
 
+	scheduler.New(postgresRepo, redisQueue, redisQueue).Start(ctx, 1*time.Second)
+
 	// Передаем redisQueue и postgresRepo (как поставщика аналитики)
-	handler := api.NewHandler(redisQueue, postgresRepo)
+	handler := api.NewHandler(redisQueue, postgresRepo,
+		api.WithRetryInspector(redisQueue),
+		api.WithDeadLetterStore(redisQueue),
+		api.WithScheduleStore(postgresRepo),
+		api.WithPayloadValidator(schemas),
+		api.WithTaskTypes(pool, redisQueue),
+		api.WithQueueInspector(redisQueue),
+		api.WithPauseController(redisQueue),
+		api.WithTaskCanceller(redisQueue),
+		api.WithProgressSource(redisQueue),
+		api.WithWorkerRegistry(redisQueue),
+		api.WithTaskWaiter(redisQueue),
+		api.WithEventSource(redisQueue),
+		api.WithCallbackLog(redisQueue),
+		api.WithLimits(api.Limits{
+			MaxRetry:   cfg.MaxTaskRetry,
+			MaxTimeout: cfg.MaxTaskTimeout,
+			MaxBackoff: cfg.MaxBackoffDelay,
+		}),
+	)
 	router := api.NewRouter(handler, m)
 
 	server := &http.Server{
@@ -113,6 +207,7 @@ func main() {
 
 	cancel()
 	pool.Stop()
+	callbacks.Stop()
 
 	logger.Info("Closing storage connections...")
 	if err := redisQueue.Close(); err != nil {
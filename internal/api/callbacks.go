@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type CallbackLog interface {
+	CallbackAttempts(ctx context.Context, id string) ([]model.CallbackAttempt, error)
+}
+
+func WithCallbackLog(l CallbackLog) Option {
+	return func(h *Handler) {
+		h.callbacks = l
+	}
+}
+
+// ListCallbackAttempts возвращает попытки доставки callback задачи.
+func (h *Handler) ListCallbackAttempts(w http.ResponseWriter, r *http.Request) {
+	if h.callbacks == nil {
+		respondError(w, http.StatusNotImplemented, "callback log is not configured")
+		return
+	}
+
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	task, err := h.queue.Get(ctx, id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if task == nil {
+		respondError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	attempts, err := h.callbacks.CallbackAttempts(ctx, id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, attempts)
+}
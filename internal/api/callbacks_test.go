@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCallbackLog map[string][]model.CallbackAttempt
+
+func (m mockCallbackLog) CallbackAttempts(ctx context.Context, id string) ([]model.CallbackAttempt, error) {
+	return m[id], nil
+}
+
+func callbacksRequest(id string) *http.Request {
+	req := httptest.NewRequest("GET", "/tasks/"+id+"/callbacks", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestListCallbackAttempts(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"1": {ID: "1", Type: "echo", Status: model.StatusCompleted},
+	}}
+	log := mockCallbackLog{"1": {
+		{Attempt: 1, Event: model.EventCompleted, StatusCode: 503, Error: "unexpected status 503"},
+		{Attempt: 2, Event: model.EventCompleted, StatusCode: 200, Delivered: true},
+	}}
+	h := NewHandler(me, nil, WithCallbackLog(log))
+
+	rr := httptest.NewRecorder()
+	h.ListCallbackAttempts(rr, callbacksRequest("1"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var attempts []model.CallbackAttempt
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &attempts))
+	require.Len(t, attempts, 2)
+	assert.True(t, attempts[1].Delivered)
+
+	rr = httptest.NewRecorder()
+	h.ListCallbackAttempts(rr, callbacksRequest("missing"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestListCallbackAttempts_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.ListCallbackAttempts(rr, callbacksRequest("1"))
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
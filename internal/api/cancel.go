@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+// TaskCanceller отменяет задачу. Для выполняющейся задачи отмена только
+// запрашивается и задача возвращается в прежнем статусе.
+type TaskCanceller interface {
+	Cancel(ctx context.Context, id string) (*model.Task, error)
+}
+
+func WithTaskCanceller(c TaskCanceller) Option {
+	return func(h *Handler) {
+		h.canceller = c
+	}
+}
+
+// CancelTask отвечает 200 с отмененной задачей, если она еще ждала запуска, и
+// 202, если задача выполняется и отмена передана воркеру.
+func (h *Handler) CancelTask(w http.ResponseWriter, r *http.Request) {
+	if h.canceller == nil {
+		respondError(w, http.StatusNotImplemented, "task cancellation is not configured")
+		return
+	}
+
+	task, err := h.canceller.Cancel(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, model.ErrTaskFinished) {
+		respondJSON(w, http.StatusConflict, ConflictResponse{Error: err.Error(), TaskID: task.ID})
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if task == nil {
+		respondError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	if task.Status != model.StatusCancelled {
+		respondJSON(w, http.StatusAccepted, task)
+		return
+	}
+	respondJSON(w, http.StatusOK, task)
+}
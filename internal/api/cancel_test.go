@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCanceller map[string]*model.Task
+
+func (m mockCanceller) Cancel(ctx context.Context, id string) (*model.Task, error) {
+	t, ok := m[id]
+	if !ok {
+		return nil, nil
+	}
+	switch t.Status {
+	case model.StatusCompleted, model.StatusFailed, model.StatusCancelled:
+		return t, model.ErrTaskFinished
+	case model.StatusProcessing:
+		return t, nil
+	}
+	t.Status = model.StatusCancelled
+	return t, nil
+}
+
+func cancelRequest(id string) *http.Request {
+	req := httptest.NewRequest("POST", "/tasks/"+id+"/cancel", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestCancelTask(t *testing.T) {
+	c := mockCanceller{
+		"pending": {ID: "pending", Status: model.StatusPending},
+		"running": {ID: "running", Status: model.StatusProcessing},
+		"done":    {ID: "done", Status: model.StatusCompleted},
+	}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithTaskCanceller(c))
+
+	tests := []struct {
+		id   string
+		code int
+	}{
+		{"pending", http.StatusOK},
+		{"running", http.StatusAccepted},
+		{"done", http.StatusConflict},
+		{"missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.CancelTask(rr, cancelRequest(tt.id))
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	h.CancelTask(rr, cancelRequest("done"))
+	var res ConflictResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, "done", res.TaskID)
+}
+
+func TestCancelTask_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.CancelTask(rr, cancelRequest("1"))
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
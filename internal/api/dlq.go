@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type DeadLetterStore interface {
+	ListDeadLetters(ctx context.Context, f model.DeadLetterFilter) ([]*model.Task, error)
+	RequeueDeadLetter(ctx context.Context, id string) (*model.Task, error)
+	RequeueDeadLettersByType(ctx context.Context, taskType string) (int, error)
+	PurgeDeadLetters(ctx context.Context) (int, error)
+}
+
+func WithDeadLetterStore(s DeadLetterStore) Option {
+	return func(h *Handler) {
+		h.dlq = s
+	}
+}
+
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.dlq == nil {
+		respondError(w, http.StatusNotImplemented, "dead letter store is not configured")
+		return
+	}
+
+	filter := model.DeadLetterFilter{
+		Type:  r.URL.Query().Get("type"),
+		Error: r.URL.Query().Get("error"),
+	}
+
+	tasks, err := h.dlq.ListDeadLetters(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tasks)
+}
+
+func (h *Handler) RequeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if h.dlq == nil {
+		respondError(w, http.StatusNotImplemented, "dead letter store is not configured")
+		return
+	}
+
+	task, err := h.dlq.RequeueDeadLetter(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, model.ErrDuplicateTask) && task != nil {
+		respondJSON(w, http.StatusConflict, ConflictResponse{Error: err.Error(), TaskID: task.ID})
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if task == nil {
+		respondError(w, http.StatusNotFound, "task not found in dead letter queue")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, task)
+}
+
+func (h *Handler) RequeueDeadLettersByType(w http.ResponseWriter, r *http.Request) {
+	if h.dlq == nil {
+		respondError(w, http.StatusNotImplemented, "dead letter store is not configured")
+		return
+	}
+
+	taskType := r.URL.Query().Get("type")
+	if taskType == "" {
+		respondError(w, http.StatusBadRequest, "type is required")
+		return
+	}
+
+	n, err := h.dlq.RequeueDeadLettersByType(r.Context(), taskType)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int{"requeued": n})
+}
+
+func (h *Handler) PurgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.dlq == nil {
+		respondError(w, http.StatusNotImplemented, "dead letter store is not configured")
+		return
+	}
+
+	n, err := h.dlq.PurgeDeadLetters(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int{"purged": n})
+}
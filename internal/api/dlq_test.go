@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDeadLetterStore struct {
+	tasks      map[string]*model.Task
+	lastFilter model.DeadLetterFilter
+	conflict   *model.Task
+}
+
+func (m *mockDeadLetterStore) ListDeadLetters(ctx context.Context, f model.DeadLetterFilter) ([]*model.Task, error) {
+	m.lastFilter = f
+	var list []*model.Task
+	for _, t := range m.tasks {
+		if f.Type == "" || t.Type == f.Type {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+
+func (m *mockDeadLetterStore) RequeueDeadLetter(ctx context.Context, id string) (*model.Task, error) {
+	if m.conflict != nil {
+		return m.conflict, model.ErrDuplicateTask
+	}
+	t, ok := m.tasks[id]
+	if !ok {
+		return nil, nil
+	}
+	delete(m.tasks, id)
+	t.Status = model.StatusPending
+	t.Retries = 0
+	return t, nil
+}
+
+func (m *mockDeadLetterStore) RequeueDeadLettersByType(ctx context.Context, taskType string) (int, error) {
+	n := 0
+	for id, t := range m.tasks {
+		if t.Type == taskType {
+			delete(m.tasks, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockDeadLetterStore) PurgeDeadLetters(ctx context.Context) (int, error) {
+	n := len(m.tasks)
+	m.tasks = map[string]*model.Task{}
+	return n, nil
+}
+
+func newDeadLetterStore() *mockDeadLetterStore {
+	return &mockDeadLetterStore{tasks: map[string]*model.Task{
+		"d1": {ID: "d1", Type: "sum", Status: model.StatusFailed, Retries: 3, Error: "invalid payload"},
+		"d2": {ID: "d2", Type: "flaky", Status: model.StatusFailed, Retries: 3, Error: "random failure"},
+	}}
+}
+
+func TestListDeadLetters_Filter(t *testing.T) {
+	ms := newDeadLetterStore()
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(ms))
+
+	req, _ := http.NewRequest("GET", "/dlq?type=sum&error=invalid", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListDeadLetters(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, model.DeadLetterFilter{Type: "sum", Error: "invalid"}, ms.lastFilter)
+	var res []model.Task
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res, 1)
+	assert.Equal(t, "d1", res[0].ID)
+}
+
+func TestRequeueDeadLetter_Success(t *testing.T) {
+	ms := newDeadLetterStore()
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(ms))
+
+	req, _ := http.NewRequest("POST", "/dlq/d1/requeue", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", "d1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	rr := httptest.NewRecorder()
+
+	h.RequeueDeadLetter(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res model.Task
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, model.StatusPending, res.Status)
+	assert.Equal(t, 0, res.Retries)
+}
+
+func TestRequeueDeadLetter_NotFound(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(newDeadLetterStore()))
+
+	req, _ := http.NewRequest("POST", "/dlq/missing/requeue", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", "missing")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	rr := httptest.NewRecorder()
+
+	h.RequeueDeadLetter(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestRequeueDeadLetter_UniqueConflict(t *testing.T) {
+	ms := newDeadLetterStore()
+	ms.conflict = &model.Task{ID: "active", Type: "sum", Status: model.StatusPending}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(ms))
+
+	req, _ := http.NewRequest("POST", "/dlq/d1/requeue", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", "d1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	rr := httptest.NewRecorder()
+
+	h.RequeueDeadLetter(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	var res ConflictResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, "active", res.TaskID)
+}
+
+func TestRequeueDeadLettersByType(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(newDeadLetterStore()))
+
+	req, _ := http.NewRequest("POST", "/dlq/requeue?type=flaky", nil)
+	rr := httptest.NewRecorder()
+
+	h.RequeueDeadLettersByType(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"requeued":1}`, rr.Body.String())
+}
+
+func TestRequeueDeadLettersByType_MissingType(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(newDeadLetterStore()))
+
+	req, _ := http.NewRequest("POST", "/dlq/requeue", nil)
+	rr := httptest.NewRecorder()
+
+	h.RequeueDeadLettersByType(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestPurgeDeadLetters(t *testing.T) {
+	ms := newDeadLetterStore()
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithDeadLetterStore(ms))
+
+	req, _ := http.NewRequest("DELETE", "/dlq", nil)
+	rr := httptest.NewRecorder()
+
+	h.PurgeDeadLetters(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"purged":2}`, rr.Body.String())
+	assert.Empty(t, ms.tasks)
+}
+
+func TestListDeadLetters_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	req, _ := http.NewRequest("GET", "/dlq", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListDeadLetters(rr, req)
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type EventSource interface {
+	SubscribeEvents(ctx context.Context) <-chan model.TaskEvent
+}
+
+func WithEventSource(s EventSource) Option {
+	return func(h *Handler) {
+		h.events = s
+	}
+}
+
+// StreamEvents отдает события жизненного цикла задач как Server-Sent Events,
+// пока клиент не отключится. Параметры task_id, type и status оставляют только
+// подходящие события; имя SSE-события совпадает с полем event.
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.events == nil {
+		respondError(w, http.StatusNotImplemented, "event source is not configured")
+		return
+	}
+
+	ctx := r.Context()
+	query := r.URL.Query()
+	filter := model.EventFilter{
+		TaskID:   query.Get("task_id"),
+		TaskType: query.Get("type"),
+		Status:   model.Status(query.Get("status")),
+	}
+
+	events := h.events.SubscribeEvents(ctx)
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if !filter.Match(e) {
+				continue
+			}
+			if err := stream.send(string(e.Event), e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := stream.keepAlive(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEvents []model.TaskEvent
+
+func (m mockEvents) SubscribeEvents(ctx context.Context) <-chan model.TaskEvent {
+	ch := make(chan model.TaskEvent, len(m))
+	for _, e := range m {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func TestStreamEvents(t *testing.T) {
+	events := mockEvents{
+		{Event: model.EventCreated, TaskID: "1", TaskType: "email", Status: model.StatusPending},
+		{Event: model.EventStarted, TaskID: "1", TaskType: "email", Status: model.StatusProcessing},
+		{Event: model.EventCreated, TaskID: "2", TaskType: "sum", Status: model.StatusPending},
+		{Event: model.EventFailed, TaskID: "1", TaskType: "email", Status: model.StatusFailed},
+	}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithEventSource(events))
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"all", "", []string{`"event":"created","task_id":"1"`, `"event":"started"`, `"task_id":"2"`, `"event":"failed"`}},
+		{"by task", "?task_id=2", []string{`"task_id":"2"`}},
+		{"by type and status", "?type=email&status=failed", []string{`"event":"failed"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.StreamEvents(rr, httptest.NewRequest("GET", "/events"+tt.query, nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+			body := rr.Body.String()
+			assert.Equal(t, len(tt.want), strings.Count(body, "event: "))
+			for _, s := range tt.want {
+				assert.Contains(t, body, s)
+			}
+		})
+	}
+}
+
+func TestStreamEvents_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.StreamEvents(rr, httptest.NewRequest("GET", "/events", nil))
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
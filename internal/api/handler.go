@@ -3,7 +3,10 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -11,7 +14,7 @@ import (
 )
 
 type TaskEnqueuer interface {
-	Push(ctx context.Context, taskType, payload string) (*model.Task, error)
+	Push(ctx context.Context, taskType string, payload json.RawMessage, opts model.EnqueueOptions) (*model.Task, error)
 	Get(ctx context.Context, id string) (*model.Task, error)
 	List(ctx context.Context) ([]*model.Task, error)
 	Delete(ctx context.Context, id string) error
@@ -21,27 +24,143 @@ type AnalyticsProvider interface {
 	GetAnalytics(ctx context.Context, from, to time.Time) (*model.AnalyticsSummary, error)
 }
 
+type RetryInspector interface {
+	ListRetries(ctx context.Context) ([]*model.Task, error)
+}
+
+// PayloadValidator проверяет тип задачи и payload при постановке в очередь.
+// Возвращает model.ErrUnknownTaskType или *model.ValidationError.
+type PayloadValidator interface {
+	Validate(taskType string, payload json.RawMessage) error
+}
+
+// Limits ограничивают переопределения политики выполнения, которые клиент
+// может задать при создании задачи.
+type Limits struct {
+	MaxRetry   int
+	MaxTimeout time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultLimits = Limits{
+	MaxRetry:   10,
+	MaxTimeout: time.Hour,
+	MaxBackoff: time.Hour,
+}
+
 type Handler struct {
 	queue     TaskEnqueuer
 	analytics AnalyticsProvider
+	retries   RetryInspector
+	dlq       DeadLetterStore
+	schedules ScheduleStore
+	queues    QueueInspector
+	pauser    PauseController
+	canceller TaskCanceller
+	progress  ProgressSource
+	workers   WorkerRegistry
+	waiter    TaskWaiter
+	events    EventSource
+	callbacks CallbackLog
+	validator PayloadValidator
+	limits    Limits
+
+	taskTypes  TaskTypeLister
+	typeDepths TypeDepthCounter
+}
+
+type Option func(*Handler)
+
+func WithPayloadValidator(v PayloadValidator) Option {
+	return func(h *Handler) {
+		h.validator = v
+	}
+}
+
+func WithLimits(l Limits) Option {
+	return func(h *Handler) {
+		h.limits = l
+	}
+}
+
+func WithRetryInspector(r RetryInspector) Option {
+	return func(h *Handler) {
+		h.retries = r
+	}
 }
 
-func NewHandler(q TaskEnqueuer, a AnalyticsProvider) *Handler {
-	return &Handler{
+func NewHandler(q TaskEnqueuer, a AnalyticsProvider, opts ...Option) *Handler {
+	h := &Handler{
 		queue:     q,
 		analytics: a,
+		limits:    DefaultLimits,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 type CreateTaskRequest struct {
-	Type    string `json:"type"`
-	Payload string `json:"payload"`
+	Type     string          `json:"type"`
+	Queue    string          `json:"queue,omitempty"`
+	Payload  json.RawMessage `json:"payload"`
+	Priority model.Priority  `json:"priority,omitempty"`
+	RunAt    *time.Time      `json:"run_at,omitempty"`
+	Delay    *model.Duration `json:"delay,omitempty"`
+
+	MaxRetry *int            `json:"max_retry,omitempty"`
+	Timeout  *model.Duration `json:"timeout,omitempty"`
+	Backoff  *model.Backoff  `json:"backoff,omitempty"`
+
+	IdempotencyKey string        `json:"idempotency_key,omitempty"`
+	Unique         UniqueRequest `json:"unique,omitempty"`
+
+	CallbackURL    string `json:"callback_url,omitempty"`
+	CallbackSecret string `json:"callback_secret,omitempty"`
+}
+
+// UniqueRequest принимает либо true/false, либо объект с ключом уникальности
+// и TTL блокировки.
+type UniqueRequest struct {
+	Enabled bool            `json:"-"`
+	Key     string          `json:"key,omitempty"`
+	TTL     *model.Duration `json:"ttl,omitempty"`
+}
+
+func (u *UniqueRequest) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*u = UniqueRequest{Enabled: enabled}
+		return nil
+	}
+
+	type plain UniqueRequest
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*u = UniqueRequest(p)
+	u.Enabled = true
+	return nil
 }
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+type ValidationErrorResponse struct {
+	Error  string             `json:"error"`
+	Fields []model.FieldError `json:"fields,omitempty"`
+}
+
+type ConflictResponse struct {
+	Error  string `json:"error"`
+	TaskID string `json:"task_id"`
+}
+
 func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	var req CreateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -54,7 +173,87 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.queue.Push(r.Context(), req.Type, req.Payload)
+	if !h.validatePayload(w, req.Type, req.Payload) {
+		return
+	}
+
+	if req.Queue != "" && !model.ValidQueueName(req.Queue) {
+		respondError(w, http.StatusBadRequest, "queue must match [A-Za-z0-9_.-] and be at most 64 characters")
+		return
+	}
+
+	priority := req.Priority.OrDefault()
+	if !priority.Valid() {
+		respondError(w, http.StatusBadRequest, "priority must be one of: high, default, low")
+		return
+	}
+
+	var runAt time.Time
+	switch {
+	case req.RunAt != nil && req.Delay != nil:
+		respondError(w, http.StatusBadRequest, "run_at and delay are mutually exclusive")
+		return
+	case req.Delay != nil:
+		if *req.Delay < 0 {
+			respondError(w, http.StatusBadRequest, "delay must not be negative")
+			return
+		}
+		runAt = time.Now().Add(time.Duration(*req.Delay))
+	case req.RunAt != nil:
+		runAt = *req.RunAt
+	}
+
+	policy, err := h.executionPolicy(req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	callback, err := taskCallback(req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var uniqueTTL time.Duration
+	if req.Unique.TTL != nil {
+		if *req.Unique.TTL <= 0 {
+			respondError(w, http.StatusBadRequest, "unique.ttl must be positive")
+			return
+		}
+		uniqueTTL = time.Duration(*req.Unique.TTL)
+	}
+
+	idempotencyKey := r.Header.Get(idempotencyKeyHeader)
+	if idempotencyKey == "" {
+		idempotencyKey = req.IdempotencyKey
+	}
+
+	task, err := h.queue.Push(r.Context(), req.Type, req.Payload, model.EnqueueOptions{
+		Queue:          req.Queue,
+		Priority:       priority,
+		RunAt:          runAt,
+		IdempotencyKey: idempotencyKey,
+		Unique:         req.Unique.Enabled,
+		UniqueKey:      req.Unique.Key,
+		UniqueTTL:      uniqueTTL,
+		Policy:         policy,
+		Callback:       callback,
+	})
+	if task == nil && (errors.Is(err, model.ErrDuplicateRequest) || errors.Is(err, model.ErrDuplicateTask)) {
+		// Исходная задача не найдена: отвечать ссылкой на нее нечем
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if errors.Is(err, model.ErrDuplicateRequest) {
+		w.Header().Set("Idempotent-Replayed", "true")
+		respondJSON(w, http.StatusOK, task)
+		return
+	}
+	if errors.Is(err, model.ErrDuplicateTask) {
+		respondJSON(w, http.StatusConflict, ConflictResponse{Error: err.Error(), TaskID: task.ID})
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -63,6 +262,88 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusCreated, task)
 }
 
+// validatePayload отвечает 422, если тип задачи неизвестен или payload не
+// соответствует схеме. Без валидатора принимается любой тип.
+func (h *Handler) validatePayload(w http.ResponseWriter, taskType string, payload json.RawMessage) bool {
+	if h.validator == nil {
+		return true
+	}
+
+	err := h.validator.Validate(taskType, payload)
+	if err == nil {
+		return true
+	}
+
+	var ve *model.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		respondJSON(w, http.StatusUnprocessableEntity, ValidationErrorResponse{
+			Error:  "payload does not match schema",
+			Fields: ve.Fields,
+		})
+	case errors.Is(err, model.ErrUnknownTaskType):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+	return false
+}
+
+// taskCallback проверяет адрес callback. Если он не задан, возвращает nil.
+func taskCallback(req CreateTaskRequest) (*model.Callback, error) {
+	if req.CallbackURL == "" {
+		if req.CallbackSecret != "" {
+			return nil, errors.New("callback_secret requires callback_url")
+		}
+		return nil, nil
+	}
+
+	u, err := url.Parse(req.CallbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("callback_url must be an absolute http or https URL")
+	}
+	return &model.Callback{URL: req.CallbackURL, Secret: req.CallbackSecret}, nil
+}
+
+// executionPolicy проверяет переопределения политики выполнения по лимитам
+// сервера. Если ничего не задано, возвращает nil.
+func (h *Handler) executionPolicy(req CreateTaskRequest) (*model.ExecutionPolicy, error) {
+	if req.MaxRetry == nil && req.Timeout == nil && req.Backoff == nil {
+		return nil, nil
+	}
+
+	policy := &model.ExecutionPolicy{MaxRetry: req.MaxRetry}
+
+	if req.MaxRetry != nil && (*req.MaxRetry < 0 || *req.MaxRetry > h.limits.MaxRetry) {
+		return nil, fmt.Errorf("max_retry must be between 0 and %d", h.limits.MaxRetry)
+	}
+
+	if req.Timeout != nil {
+		if *req.Timeout <= 0 || time.Duration(*req.Timeout) > h.limits.MaxTimeout {
+			return nil, fmt.Errorf("timeout must be positive and at most %s", h.limits.MaxTimeout)
+		}
+		policy.Timeout = *req.Timeout
+	}
+
+	if req.Backoff != nil {
+		b := *req.Backoff
+		if err := b.Validate(); err != nil {
+			return nil, err
+		}
+		maxBackoff := model.Duration(h.limits.MaxBackoff)
+		if b.Delay > maxBackoff || b.MaxDelay > maxBackoff {
+			return nil, fmt.Errorf("backoff delays must be at most %s", h.limits.MaxBackoff)
+		}
+		// Экспоненциальный рост тоже не должен выходить за лимит
+		if b.MaxDelay == 0 {
+			b.MaxDelay = maxBackoff
+		}
+		policy.Backoff = &b
+	}
+
+	return policy, nil
+}
+
 func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -77,6 +358,14 @@ func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.progress != nil {
+		task.Progress, err = h.progress.Progress(r.Context(), id)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
 	respondJSON(w, http.StatusOK, task)
 }
 
@@ -87,6 +376,16 @@ func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if status := model.Status(r.URL.Query().Get("status")); status != "" {
+		filtered := make([]*model.Task, 0, len(tasks))
+		for _, t := range tasks {
+			if t.Status == status {
+				filtered = append(filtered, t)
+			}
+		}
+		tasks = filtered
+	}
+
 	respondJSON(w, http.StatusOK, tasks)
 }
 
@@ -112,6 +411,21 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) ListRetries(w http.ResponseWriter, r *http.Request) {
+	if h.retries == nil {
+		respondError(w, http.StatusNotImplemented, "retry inspector is not configured")
+		return
+	}
+
+	tasks, err := h.retries.ListRetries(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tasks)
+}
+
 func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 	if h.analytics == nil {
 		respondError(w, http.StatusNotImplemented, "analytics provider is not configured")
@@ -148,7 +462,22 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+	json.NewEncoder(w).Encode(redactSecrets(data))
+}
+
+// redactSecrets убирает секреты callback из задач перед отправкой клиенту.
+func redactSecrets(data any) any {
+	switch v := data.(type) {
+	case *model.Task:
+		return v.Redacted()
+	case []*model.Task:
+		redacted := make([]*model.Task, len(v))
+		for i, t := range v {
+			redacted[i] = t.Redacted()
+		}
+		return redacted
+	}
+	return data
 }
 
 func respondError(w http.ResponseWriter, status int, message string) {
@@ -12,6 +12,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/podushkina/taskqueue/internal/schema"
+	"github.com/podushkina/taskqueue/internal/worker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,13 +21,27 @@ import (
 type mockFullEnqueuer struct {
 	tasks      map[string]*model.Task
 	errToThrow error
+	lastOpts   model.EnqueueOptions
 }
 
-func (m *mockFullEnqueuer) Push(ctx context.Context, taskType, payload string) (*model.Task, error) {
+func (m *mockFullEnqueuer) Push(ctx context.Context, taskType string, payload json.RawMessage, opts model.EnqueueOptions) (*model.Task, error) {
 	if m.errToThrow != nil {
 		return nil, m.errToThrow
 	}
-	t := &model.Task{ID: "generated-id", Type: taskType, Payload: payload, Status: model.StatusPending}
+	m.lastOpts = opts
+	for _, existing := range m.tasks {
+		if opts.IdempotencyKey != "" && existing.IdempotencyKey == opts.IdempotencyKey {
+			return existing, model.ErrDuplicateRequest
+		}
+		if opts.Unique && existing.UniqueKey != "" && existing.UniqueKey == taskType+":"+opts.UniqueKey {
+			return existing, model.ErrDuplicateTask
+		}
+	}
+	t := &model.Task{ID: "generated-id", IdempotencyKey: opts.IdempotencyKey, Type: taskType, Payload: payload, Status: model.StatusPending, Priority: opts.Priority}
+	if !opts.RunAt.IsZero() {
+		t.Status = model.StatusScheduled
+		t.RunAt = &opts.RunAt
+	}
 	m.tasks["generated-id"] = t
 	return t, nil
 }
@@ -86,6 +102,364 @@ func TestCreateTask_Success(t *testing.T) {
 	assert.Equal(t, "echo", res.Type)
 }
 
+func TestCreateTask_Priority(t *testing.T) {
+	cases := []struct {
+		body string
+		want model.Priority
+	}{
+		{`{"type":"echo"}`, model.PriorityDefault},
+		{`{"type":"echo","priority":"HIGH"}`, model.PriorityHigh},
+		{`{"type":"echo","priority":-5}`, model.PriorityLow},
+		{`{"type":"echo","priority":0}`, model.PriorityDefault},
+	}
+
+	for _, tc := range cases {
+		me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+		h := NewHandler(me, nil)
+
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(tc.body))
+		rr := httptest.NewRecorder()
+
+		h.CreateTask(rr, req)
+
+		require.Equal(t, http.StatusCreated, rr.Code, tc.body)
+		assert.Equal(t, tc.want, me.tasks["generated-id"].Priority, tc.body)
+	}
+}
+
+func TestCreateTask_InvalidPriority(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","priority":"urgent"}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Empty(t, me.tasks)
+}
+
+func TestCreateTask_Delay(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","delay":"10m"}`))
+	rr := httptest.NewRecorder()
+
+	before := time.Now()
+	h.CreateTask(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	task := me.tasks["generated-id"]
+	assert.Equal(t, model.StatusScheduled, task.Status)
+	require.NotNil(t, task.RunAt)
+	assert.WithinDuration(t, before.Add(10*time.Minute), *task.RunAt, time.Second)
+}
+
+func TestCreateTask_RunAt(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","run_at":"2030-01-01T03:00:00Z"}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NotNil(t, me.tasks["generated-id"].RunAt)
+	assert.True(t, time.Date(2030, 1, 1, 3, 0, 0, 0, time.UTC).Equal(*me.tasks["generated-id"].RunAt))
+}
+
+func TestCreateTask_RunAtAndDelay(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","run_at":"2030-01-01T03:00:00Z","delay":60}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestCreateTask_IdempotencyKeyHeader(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"original": {ID: "original", Type: "echo", IdempotencyKey: "abc"},
+	}}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","idempotency_key":"ignored"}`))
+	req.Header.Set("Idempotency-Key", "abc")
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "abc", me.lastOpts.IdempotencyKey)
+	var res model.Task
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, "original", res.ID)
+}
+
+func TestCreateTask_IdempotencyKeyField(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","idempotency_key":"k1"}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "k1", me.lastOpts.IdempotencyKey)
+}
+
+func TestCreateTask_UniqueConflict(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"running": {ID: "running", Type: "reindex", UniqueKey: "reindex:customer-1"},
+	}}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"reindex","unique":{"key":"customer-1","ttl":"1h"}}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, time.Hour, me.lastOpts.UniqueTTL)
+	var res ConflictResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, "running", res.TaskID)
+}
+
+func TestCreateTask_DuplicateWithoutTask(t *testing.T) {
+	for _, dupErr := range []error{model.ErrDuplicateTask, model.ErrDuplicateRequest} {
+		me := &mockFullEnqueuer{tasks: make(map[string]*model.Task), errToThrow: dupErr}
+		h := NewHandler(me, nil)
+
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"reindex","unique":true}`))
+		rr := httptest.NewRecorder()
+
+		require.NotPanics(t, func() { h.CreateTask(rr, req) })
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	}
+}
+
+func TestCreateTask_UniqueFlag(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"reindex","payload":"x","unique":true}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.True(t, me.lastOpts.Unique)
+	assert.Empty(t, me.lastOpts.UniqueKey)
+}
+
+func TestCreateTask_Queue(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","queue":"emails"}`))
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "emails", me.lastOpts.Queue)
+
+	req, _ = http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","queue":"bad:name"}`))
+	rr = httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestCreateTask_Callback(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"echo","callback_url":"https://example.com/hook","callback_secret":"s3cret"}`))
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	require.NotNil(t, me.lastOpts.Callback)
+	assert.Equal(t, model.Callback{URL: "https://example.com/hook", Secret: "s3cret"}, *me.lastOpts.Callback)
+
+	for _, body := range []string{
+		`{"type":"echo","callback_url":"ftp://example.com/hook"}`,
+		`{"type":"echo","callback_url":"/hook"}`,
+		`{"type":"echo","callback_secret":"s3cret"}`,
+	} {
+		req, _ = http.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+		rr = httptest.NewRecorder()
+		h.CreateTask(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestGetTask_RedactsCallbackSecret(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"1": {ID: "1", Type: "echo", Callback: &model.Callback{URL: "https://example.com/hook", Secret: "s3cret"}},
+	}}
+	h := NewHandler(me, nil)
+
+	req := httptest.NewRequest("GET", "/tasks/1", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	rr := httptest.NewRecorder()
+	h.GetTask(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "https://example.com/hook")
+	assert.NotContains(t, rr.Body.String(), "s3cret")
+	// Хранимая задача не изменилась
+	assert.Equal(t, "s3cret", me.tasks["1"].Callback.Secret)
+
+	rr = httptest.NewRecorder()
+	h.ListTasks(rr, httptest.NewRequest("GET", "/tasks", nil))
+	assert.NotContains(t, rr.Body.String(), "s3cret")
+}
+
+func TestCreateTask_ExecutionPolicy(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	body := `{"type":"echo","max_retry":5,"timeout":"2m","backoff":{"strategy":"linear","delay":"10s"}}`
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	policy := me.lastOpts.Policy
+	require.NotNil(t, policy)
+	assert.Equal(t, 5, *policy.MaxRetry)
+	assert.Equal(t, model.Duration(2*time.Minute), policy.Timeout)
+	assert.Equal(t, model.BackoffLinear, policy.Backoff.Strategy)
+	assert.Equal(t, model.Duration(DefaultLimits.MaxBackoff), policy.Backoff.MaxDelay)
+}
+
+func TestCreateTask_ExecutionPolicyLimits(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{tasks: make(map[string]*model.Task)}, nil,
+		WithLimits(Limits{MaxRetry: 5, MaxTimeout: time.Minute, MaxBackoff: time.Minute}))
+
+	cases := []string{
+		`{"type":"echo","max_retry":6}`,
+		`{"type":"echo","max_retry":-1}`,
+		`{"type":"echo","timeout":"2m"}`,
+		`{"type":"echo","timeout":0}`,
+		`{"type":"echo","backoff":{"strategy":"random"}}`,
+		`{"type":"echo","backoff":{"strategy":"fixed","delay":"5m"}}`,
+	}
+	for _, body := range cases {
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		h.CreateTask(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestCreateTask_JSONPayload(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"sum","payload":[1, 2, 3]}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var res struct {
+		Payload []int `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, []int{1, 2, 3}, res.Payload)
+}
+
+func TestCreateTask_StringEncodedPayload(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	registry := schema.NewRegistry()
+	require.NoError(t, registry.Register("sum", worker.SumSchema))
+	h := NewHandler(me, nil, WithPayloadValidator(registry))
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"sum","payload":"[10,20,30]"}`))
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	req, _ = http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"sum","payload":"[10,\"x\"]"}`))
+	rr = httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
+
+type mockValidator struct{}
+
+func (mockValidator) Validate(taskType string, payload json.RawMessage) error {
+	switch taskType {
+	case "echo":
+		return nil
+	case "sum":
+		var numbers []float64
+		if json.Unmarshal(payload, &numbers) != nil {
+			return &model.ValidationError{Fields: []model.FieldError{{Field: "", Message: "expected array"}}}
+		}
+		return nil
+	}
+	return model.ErrUnknownTaskType
+}
+
+func TestCreateTask_PayloadValidation(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil, WithPayloadValidator(mockValidator{}))
+
+	cases := []struct {
+		body string
+		code int
+	}{
+		{`{"type":"sum","payload":[1,2]}`, http.StatusCreated},
+		{`{"type":"sum","payload":"1,2"}`, http.StatusUnprocessableEntity},
+		{`{"type":"resize","payload":{}}`, http.StatusUnprocessableEntity},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(c.body))
+		rr := httptest.NewRecorder()
+
+		h.CreateTask(rr, req)
+
+		assert.Equal(t, c.code, rr.Code, c.body)
+	}
+}
+
+func TestCreateTask_PayloadValidationFields(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil, WithPayloadValidator(mockValidator{}))
+
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"type":"sum","payload":{"a":1}}`))
+	rr := httptest.NewRecorder()
+
+	h.CreateTask(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	var res ValidationErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res.Fields, 1)
+	assert.Equal(t, "expected array", res.Fields[0].Message)
+	assert.Empty(t, me.tasks)
+}
+
 func TestCreateTask_MissingType(t *testing.T) {
 	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
 	h := NewHandler(me, nil)
@@ -192,6 +566,25 @@ func TestListTasks_Success(t *testing.T) {
 	assert.Len(t, res, 2)
 }
 
+func TestListTasks_FilterByStatus(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"1": {ID: "1", Status: model.StatusScheduled},
+		"2": {ID: "2", Status: model.StatusPending},
+	}}
+	h := NewHandler(me, nil)
+
+	req, _ := http.NewRequest("GET", "/tasks?status=scheduled", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListTasks(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res []model.Task
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res, 1)
+	assert.Equal(t, "1", res[0].ID)
+}
+
 func TestDeleteTask_Success(t *testing.T) {
 	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
 		"del": {ID: "del"},
@@ -265,6 +658,45 @@ func TestGetAnalytics_InternalError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 }
 
+type mockRetryInspector struct {
+	tasks []*model.Task
+}
+
+func (m *mockRetryInspector) ListRetries(ctx context.Context) ([]*model.Task, error) {
+	return m.tasks, nil
+}
+
+func TestListRetries_Success(t *testing.T) {
+	due := time.Now().Add(time.Minute)
+	mr := &mockRetryInspector{tasks: []*model.Task{
+		{ID: "r1", Type: "flaky", Retries: 1, NextRetryAt: &due},
+	}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithRetryInspector(mr))
+
+	req, _ := http.NewRequest("GET", "/retries", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListRetries(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res []model.Task
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res, 1)
+	assert.Equal(t, "r1", res[0].ID)
+	assert.NotNil(t, res[0].NextRetryAt)
+}
+
+func TestListRetries_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	req, _ := http.NewRequest("GET", "/retries", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListRetries(rr, req)
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
 func TestHealthCheck(t *testing.T) {
 	h := NewHandler(&mockFullEnqueuer{}, nil)
 	req, _ := http.NewRequest("GET", "/health", nil)
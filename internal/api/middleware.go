@@ -20,6 +20,12 @@ func (rw *responseWriterInterceptor) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap нужен http.ResponseController, чтобы потоковые ручки могли сбрасывать
+// буфер и снимать таймаут записи.
+func (rw *responseWriterInterceptor) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func MetricsMiddleware(m HTTPMetricsRecorder) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type ProgressSource interface {
+	Progress(ctx context.Context, id string) (*model.Progress, error)
+	SubscribeProgress(ctx context.Context, id string) <-chan model.Progress
+}
+
+func WithProgressSource(p ProgressSource) Option {
+	return func(h *Handler) {
+		h.progress = p
+	}
+}
+
+// StreamProgress отдает прогресс задачи как Server-Sent Events: текущее
+// значение, затем каждое обновление. Поток завершается событием done, когда
+// задача завершена, или при отключении клиента.
+func (h *Handler) StreamProgress(w http.ResponseWriter, r *http.Request) {
+	if h.progress == nil {
+		respondError(w, http.StatusNotImplemented, "progress source is not configured")
+		return
+	}
+
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	task, err := h.queue.Get(ctx, id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if task == nil {
+		respondError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	// Подписываемся до чтения текущего значения, чтобы не пропустить обновление
+	updates := h.progress.SubscribeProgress(ctx, id)
+
+	current, err := h.progress.Progress(ctx, id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if current != nil {
+		if err := stream.send("progress", current); err != nil {
+			return
+		}
+	}
+	if task.Status.Finished() {
+		_ = stream.send("done", task)
+		return
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := stream.send("progress", p); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// Заодно проверяем, не завершилась ли задача
+			task, err := h.queue.Get(ctx, id)
+			if err != nil {
+				return
+			}
+			if task == nil || task.Status.Finished() {
+				_ = stream.send("done", task)
+				return
+			}
+			if err := stream.keepAlive(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockProgress struct {
+	current *model.Progress
+	updates chan model.Progress
+}
+
+func (m *mockProgress) Progress(ctx context.Context, id string) (*model.Progress, error) {
+	return m.current, nil
+}
+
+func (m *mockProgress) SubscribeProgress(ctx context.Context, id string) <-chan model.Progress {
+	return m.updates
+}
+
+func progressRequest(id string) *http.Request {
+	req := httptest.NewRequest("GET", "/tasks/"+id+"/progress", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestGetTask_WithProgress(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"1": {ID: "1", Type: "slow", Status: model.StatusProcessing},
+	}}
+	p := &mockProgress{current: &model.Progress{Percent: 40, Message: "step 2 of 5"}}
+	h := NewHandler(me, nil, WithProgressSource(p))
+
+	req := httptest.NewRequest("GET", "/tasks/1", nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	rr := httptest.NewRecorder()
+	h.GetTask(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res model.Task
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.NotNil(t, res.Progress)
+	assert.Equal(t, 40.0, res.Progress.Percent)
+	assert.Equal(t, "step 2 of 5", res.Progress.Message)
+}
+
+func TestStreamProgress(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"1": {ID: "1", Type: "slow", Status: model.StatusProcessing},
+	}}
+	p := &mockProgress{
+		current: &model.Progress{Percent: 20},
+		updates: make(chan model.Progress, 2),
+	}
+	p.updates <- model.Progress{Percent: 60, Message: "step 3 of 5"}
+	close(p.updates)
+	h := NewHandler(me, nil, WithProgressSource(p))
+
+	rr := httptest.NewRecorder()
+	h.StreamProgress(rr, progressRequest("1"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	body := rr.Body.String()
+	assert.Contains(t, body, "event: progress\ndata: {\"percent\":20,")
+	assert.Contains(t, body, "event: progress\ndata: {\"percent\":60,\"message\":\"step 3 of 5\",")
+}
+
+func TestStreamProgress_FinishedTask(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: map[string]*model.Task{
+		"1": {ID: "1", Type: "slow", Status: model.StatusCompleted},
+	}}
+	h := NewHandler(me, nil, WithProgressSource(&mockProgress{current: &model.Progress{Percent: 100}}))
+
+	rr := httptest.NewRecorder()
+	h.StreamProgress(rr, progressRequest("1"))
+
+	assert.Contains(t, rr.Body.String(), "event: progress\n")
+	assert.Contains(t, rr.Body.String(), "event: done\ndata: {\"id\":\"1\"")
+}
+
+func TestStreamProgress_NotFound(t *testing.T) {
+	me := &mockFullEnqueuer{tasks: make(map[string]*model.Task)}
+	h := NewHandler(me, nil, WithProgressSource(&mockProgress{}))
+
+	rr := httptest.NewRecorder()
+	h.StreamProgress(rr, progressRequest("missing"))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type QueueInspector interface {
+	QueueStats(ctx context.Context) ([]model.QueueStats, error)
+}
+
+// PauseController приостанавливает и возобновляет выдачу задач очередей и
+// типов всем пулам.
+type PauseController interface {
+	PauseQueue(ctx context.Context, name string) error
+	ResumeQueue(ctx context.Context, name string) error
+	PauseType(ctx context.Context, taskType string) error
+	ResumeType(ctx context.Context, taskType string) error
+	PausedTypes(ctx context.Context) (map[string]bool, error)
+}
+
+func WithPauseController(p PauseController) Option {
+	return func(h *Handler) {
+		h.pauser = p
+	}
+}
+
+type PauseResponse struct {
+	Name   string `json:"name"`
+	Paused bool   `json:"paused"`
+}
+
+func WithQueueInspector(q QueueInspector) Option {
+	return func(h *Handler) {
+		h.queues = q
+	}
+}
+
+func (h *Handler) ListQueues(w http.ResponseWriter, r *http.Request) {
+	if h.queues == nil {
+		respondError(w, http.StatusNotImplemented, "queue inspector is not configured")
+		return
+	}
+
+	stats, err := h.queues.QueueStats(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) PauseQueue(w http.ResponseWriter, r *http.Request) {
+	h.setQueuePaused(w, r, true)
+}
+
+func (h *Handler) ResumeQueue(w http.ResponseWriter, r *http.Request) {
+	h.setQueuePaused(w, r, false)
+}
+
+func (h *Handler) setQueuePaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if h.pauser == nil {
+		respondError(w, http.StatusNotImplemented, "pause controller is not configured")
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	if !model.ValidQueueName(name) {
+		respondError(w, http.StatusBadRequest, "invalid queue name")
+		return
+	}
+
+	set := h.pauser.ResumeQueue
+	if paused {
+		set = h.pauser.PauseQueue
+	}
+	if err := set(r.Context(), name); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, PauseResponse{Name: name, Paused: paused})
+}
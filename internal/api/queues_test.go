@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueueInspector []model.QueueStats
+
+func (m mockQueueInspector) QueueStats(ctx context.Context) ([]model.QueueStats, error) {
+	return m, nil
+}
+
+type mockPauser struct {
+	queues map[string]bool
+	types  map[string]bool
+}
+
+func newMockPauser() *mockPauser {
+	return &mockPauser{queues: make(map[string]bool), types: make(map[string]bool)}
+}
+
+func (m *mockPauser) PauseQueue(ctx context.Context, name string) error {
+	m.queues[name] = true
+	return nil
+}
+
+func (m *mockPauser) ResumeQueue(ctx context.Context, name string) error {
+	delete(m.queues, name)
+	return nil
+}
+
+func (m *mockPauser) PauseType(ctx context.Context, taskType string) error {
+	m.types[taskType] = true
+	return nil
+}
+
+func (m *mockPauser) ResumeType(ctx context.Context, taskType string) error {
+	delete(m.types, taskType)
+	return nil
+}
+
+func (m *mockPauser) PausedTypes(ctx context.Context) (map[string]bool, error) {
+	return m.types, nil
+}
+
+func withName(req *http.Request, name string) *http.Request {
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("name", name)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestListQueues(t *testing.T) {
+	stats := mockQueueInspector{
+		{Name: "default", Pending: 2, Window: model.Duration(5 * time.Minute)},
+		{Name: "emails", Pending: 7, Completed: 10, Failed: 1, Throughput: 2, Window: model.Duration(5 * time.Minute)},
+	}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithQueueInspector(stats))
+
+	rr := httptest.NewRecorder()
+	h.ListQueues(rr, httptest.NewRequest("GET", "/queues", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res []model.QueueStats
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	assert.Equal(t, stats[1], res[1])
+	assert.Contains(t, rr.Body.String(), `"throughput_per_minute":2`)
+	assert.Contains(t, rr.Body.String(), `"window":"5m0s"`)
+}
+
+func TestListQueues_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.ListQueues(rr, httptest.NewRequest("GET", "/queues", nil))
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
+
+func TestPauseResumeQueue(t *testing.T) {
+	p := newMockPauser()
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithPauseController(p))
+
+	rr := httptest.NewRecorder()
+	h.PauseQueue(rr, withName(httptest.NewRequest("POST", "/queues/emails/pause", nil), "emails"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"name":"emails","paused":true}`, rr.Body.String())
+	assert.True(t, p.queues["emails"])
+
+	rr = httptest.NewRecorder()
+	h.ResumeQueue(rr, withName(httptest.NewRequest("POST", "/queues/emails/resume", nil), "emails"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"name":"emails","paused":false}`, rr.Body.String())
+	assert.False(t, p.queues["emails"])
+
+	rr = httptest.NewRecorder()
+	h.PauseQueue(rr, withName(httptest.NewRequest("POST", "/queues/a:b/pause", nil), "a:b"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestPauseTaskType(t *testing.T) {
+	p := newMockPauser()
+	types := mockTaskTypes{{Name: "echo"}, {Name: "slow"}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithTaskTypes(types, nil), WithPauseController(p))
+
+	rr := httptest.NewRecorder()
+	h.PauseTaskType(rr, withName(httptest.NewRequest("POST", "/task-types/slow/pause", nil), "slow"))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	h.ListTaskTypes(rr, httptest.NewRequest("GET", "/task-types", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res []model.TaskType
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	assert.False(t, res[0].Paused)
+	assert.True(t, res[1].Paused)
+}
+
+func TestPauseQueue_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.PauseQueue(rr, withName(httptest.NewRequest("POST", "/queues/emails/pause", nil), "emails"))
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
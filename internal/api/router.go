@@ -17,12 +17,46 @@ func NewRouter(h *Handler, m HTTPMetricsRecorder) *chi.Mux {
 	r.Handle("/metrics", promhttp.Handler())
 	r.Get("/health", h.HealthCheck)
 	r.Get("/analytics", h.GetAnalytics)
+	r.Get("/retries", h.ListRetries)
+	r.Get("/workers", h.ListWorkers)
+	r.Get("/events", h.StreamEvents)
+
+	r.Route("/task-types", func(r chi.Router) {
+		r.Get("/", h.ListTaskTypes)
+		r.Post("/{name}/pause", h.PauseTaskType)
+		r.Post("/{name}/resume", h.ResumeTaskType)
+	})
+
+	r.Route("/queues", func(r chi.Router) {
+		r.Get("/", h.ListQueues)
+		r.Post("/{name}/pause", h.PauseQueue)
+		r.Post("/{name}/resume", h.ResumeQueue)
+	})
 
 	r.Route("/tasks", func(r chi.Router) {
 		r.Post("/", h.CreateTask)
 		r.Get("/", h.ListTasks)
 		r.Get("/{id}", h.GetTask)
 		r.Delete("/{id}", h.DeleteTask)
+		r.Post("/{id}/cancel", h.CancelTask)
+		r.Get("/{id}/progress", h.StreamProgress)
+		r.Get("/{id}/wait", h.WaitTask)
+		r.Get("/{id}/callbacks", h.ListCallbackAttempts)
+	})
+
+	r.Route("/schedules", func(r chi.Router) {
+		r.Post("/", h.CreateSchedule)
+		r.Get("/", h.ListSchedules)
+		r.Get("/{id}", h.GetSchedule)
+		r.Put("/{id}", h.UpdateSchedule)
+		r.Delete("/{id}", h.DeleteSchedule)
+	})
+
+	r.Route("/dlq", func(r chi.Router) {
+		r.Get("/", h.ListDeadLetters)
+		r.Delete("/", h.PurgeDeadLetters)
+		r.Post("/requeue", h.RequeueDeadLettersByType)
+		r.Post("/{id}/requeue", h.RequeueDeadLetter)
 	})
 
 	return r
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type ScheduleStore interface {
+	CreateSchedule(ctx context.Context, s *model.Schedule) error
+	GetSchedule(ctx context.Context, id string) (*model.Schedule, error)
+	ListSchedules(ctx context.Context) ([]*model.Schedule, error)
+	UpdateSchedule(ctx context.Context, s *model.Schedule) (bool, error)
+	DeleteSchedule(ctx context.Context, id string) (bool, error)
+}
+
+func WithScheduleStore(s ScheduleStore) Option {
+	return func(h *Handler) {
+		h.schedules = s
+	}
+}
+
+type ScheduleRequest struct {
+	Name     string          `json:"name"`
+	Cron     string          `json:"cron"`
+	Timezone string          `json:"timezone"`
+	Type     string          `json:"type"`
+	Payload  json.RawMessage `json:"payload"`
+	Queue    string          `json:"queue,omitempty"`
+	Priority model.Priority  `json:"priority,omitempty"`
+	Enabled  *bool           `json:"enabled,omitempty"`
+}
+
+// Ограничения длины полей совпадают с размерами столбцов VARCHAR таблицы
+// schedules (migrations/00003_create_schedules.sql). Длину queue ограничивает
+// model.ValidQueueName.
+const (
+	maxScheduleNameLen     = 100
+	maxScheduleCronLen     = 100
+	maxScheduleTimezoneLen = 64
+	maxScheduleTypeLen     = 50
+)
+
+// apply проверяет запрос и заполняет поля расписания, включая next_run_at.
+func (req *ScheduleRequest) apply(s *model.Schedule, now time.Time) error {
+	if req.Type == "" {
+		return errors.New("type is required")
+	}
+	if req.Cron == "" {
+		return errors.New("cron is required")
+	}
+
+	s.Name = req.Name
+	if s.Name == "" {
+		s.Name = req.Type
+	}
+	s.Cron = req.Cron
+	s.Timezone = req.Timezone
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	s.Type = req.Type
+	s.Payload = req.Payload
+	s.Queue = req.Queue
+	if s.Queue == "" {
+		s.Queue = model.DefaultQueue
+	}
+	if !model.ValidQueueName(s.Queue) {
+		return errors.New("queue must match [A-Za-z0-9_.-] and be at most 64 characters")
+	}
+	s.Priority = req.Priority.OrDefault()
+	if !s.Priority.Valid() {
+		return errors.New("priority must be one of: high, default, low")
+	}
+	if err := checkLength("name", s.Name, maxScheduleNameLen); err != nil {
+		return err
+	}
+	if err := checkLength("cron", s.Cron, maxScheduleCronLen); err != nil {
+		return err
+	}
+	if err := checkLength("timezone", s.Timezone, maxScheduleTimezoneLen); err != nil {
+		return err
+	}
+	if err := checkLength("type", s.Type, maxScheduleTypeLen); err != nil {
+		return err
+	}
+	s.Enabled = req.Enabled == nil || *req.Enabled
+	s.UpdatedAt = now
+
+	next, err := s.NextAfter(now)
+	if err != nil {
+		return err
+	}
+	s.NextRunAt = next
+	return nil
+}
+
+// checkLength считает символы, а не байты: так же ограничивает длину VARCHAR(n)
+// в PostgreSQL.
+func checkLength(field, value string, max int) error {
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s must be at most %d characters", field, max)
+	}
+	return nil
+}
+
+func (h *Handler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		respondError(w, http.StatusNotImplemented, "schedule store is not configured")
+		return
+	}
+
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	now := time.Now()
+	s := &model.Schedule{ID: uuid.New().String(), CreatedAt: now}
+	if err := req.apply(s, now); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.validatePayload(w, s.Type, s.Payload) {
+		return
+	}
+
+	if err := h.schedules.CreateSchedule(r.Context(), s); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, s)
+}
+
+func (h *Handler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		respondError(w, http.StatusNotImplemented, "schedule store is not configured")
+		return
+	}
+
+	list, err := h.schedules.ListSchedules(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		respondError(w, http.StatusNotImplemented, "schedule store is not configured")
+		return
+	}
+
+	s, err := h.schedules.GetSchedule(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if s == nil {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, s)
+}
+
+func (h *Handler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		respondError(w, http.StatusNotImplemented, "schedule store is not configured")
+		return
+	}
+
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	s, err := h.schedules.GetSchedule(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if s == nil {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	if err := req.apply(s, time.Now()); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.validatePayload(w, s.Type, s.Payload) {
+		return
+	}
+
+	found, err := h.schedules.UpdateSchedule(r.Context(), s)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !found {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, s)
+}
+
+func (h *Handler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if h.schedules == nil {
+		respondError(w, http.StatusNotImplemented, "schedule store is not configured")
+		return
+	}
+
+	found, err := h.schedules.DeleteSchedule(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !found {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockScheduleStore struct {
+	schedules map[string]*model.Schedule
+}
+
+func (m *mockScheduleStore) CreateSchedule(ctx context.Context, s *model.Schedule) error {
+	m.schedules[s.ID] = s
+	return nil
+}
+
+func (m *mockScheduleStore) GetSchedule(ctx context.Context, id string) (*model.Schedule, error) {
+	s, ok := m.schedules[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (m *mockScheduleStore) ListSchedules(ctx context.Context) ([]*model.Schedule, error) {
+	list := make([]*model.Schedule, 0, len(m.schedules))
+	for _, s := range m.schedules {
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func (m *mockScheduleStore) UpdateSchedule(ctx context.Context, s *model.Schedule) (bool, error) {
+	if _, ok := m.schedules[s.ID]; !ok {
+		return false, nil
+	}
+	m.schedules[s.ID] = s
+	return true, nil
+}
+
+func (m *mockScheduleStore) DeleteSchedule(ctx context.Context, id string) (bool, error) {
+	if _, ok := m.schedules[id]; !ok {
+		return false, nil
+	}
+	delete(m.schedules, id)
+	return true, nil
+}
+
+func withID(req *http.Request, id string) *http.Request {
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestCreateSchedule_Success(t *testing.T) {
+	ms := &mockScheduleStore{schedules: map[string]*model.Schedule{}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithScheduleStore(ms))
+
+	body := `{"name":"nightly","cron":"0 3 * * *","timezone":"Europe/Moscow","type":"echo","payload":"hi"}`
+	req, _ := http.NewRequest("POST", "/schedules", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	h.CreateSchedule(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var res model.Schedule
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.NotEmpty(t, res.ID)
+	assert.True(t, res.Enabled)
+	assert.Equal(t, model.PriorityDefault, res.Priority)
+	assert.Equal(t, model.DefaultQueue, res.Queue)
+	assert.False(t, res.NextRunAt.IsZero())
+	assert.Len(t, ms.schedules, 1)
+}
+
+func TestCreateSchedule_Validation(t *testing.T) {
+	cases := []string{
+		`{"cron":"0 3 * * *"}`,
+		`{"type":"echo"}`,
+		`{"type":"echo","cron":"not a cron"}`,
+		`{"type":"echo","cron":"0 0 30 2 *"}`,
+		`{"type":"echo","cron":"0 3 * * *","timezone":"Mars/Olympus"}`,
+		`{"type":"echo","cron":"0 3 * * *","priority":"urgent"}`,
+		`{"type":"echo","cron":"0 3 * * *","queue":"bad:queue"}`,
+		`{"type":"echo","cron":"0 3 * * *","name":"` + strings.Repeat("n", 101) + `"}`,
+		`{"type":"echo","cron":"0 3 * * *` + strings.Repeat(" ", 100) + `"}`,
+		`{"type":"echo","cron":"0 3 * * *","timezone":"` + strings.Repeat("z", 65) + `"}`,
+		`{"type":"` + strings.Repeat("t", 51) + `","cron":"0 3 * * *"}`,
+	}
+
+	for _, body := range cases {
+		ms := &mockScheduleStore{schedules: map[string]*model.Schedule{}}
+		h := NewHandler(&mockFullEnqueuer{}, nil, WithScheduleStore(ms))
+
+		req, _ := http.NewRequest("POST", "/schedules", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		h.CreateSchedule(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		assert.Empty(t, ms.schedules, body)
+	}
+}
+
+func TestUpdateSchedule_Success(t *testing.T) {
+	ms := &mockScheduleStore{schedules: map[string]*model.Schedule{
+		"s1": {ID: "s1", Name: "old", Cron: "0 3 * * *", Timezone: "UTC", Type: "echo", Enabled: true},
+	}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithScheduleStore(ms))
+
+	body := `{"cron":"*/5 * * * *","type":"sum","payload":"[1,2]","enabled":false}`
+	req, _ := http.NewRequest("PUT", "/schedules/s1", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	h.UpdateSchedule(rr, withID(req, "s1"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "*/5 * * * *", ms.schedules["s1"].Cron)
+	assert.Equal(t, "sum", ms.schedules["s1"].Type)
+	assert.False(t, ms.schedules["s1"].Enabled)
+}
+
+func TestUpdateSchedule_TooLong(t *testing.T) {
+	ms := &mockScheduleStore{schedules: map[string]*model.Schedule{
+		"s1": {ID: "s1", Name: "old", Cron: "0 3 * * *", Timezone: "UTC", Type: "echo", Enabled: true},
+	}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithScheduleStore(ms))
+
+	body := `{"cron":"@hourly","type":"echo","name":"` + strings.Repeat("я", 101) + `"}`
+	req, _ := http.NewRequest("PUT", "/schedules/s1", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	h.UpdateSchedule(rr, withID(req, "s1"))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "old", ms.schedules["s1"].Name)
+
+	// Длина считается в символах, как у VARCHAR: 100 букв кириллицы допустимы
+	body = `{"cron":"@hourly","type":"echo","name":"` + strings.Repeat("я", 100) + `"}`
+	req, _ = http.NewRequest("PUT", "/schedules/s1", bytes.NewBufferString(body))
+	rr = httptest.NewRecorder()
+
+	h.UpdateSchedule(rr, withID(req, "s1"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestUpdateSchedule_NotFound(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithScheduleStore(&mockScheduleStore{schedules: map[string]*model.Schedule{}}))
+
+	req, _ := http.NewRequest("PUT", "/schedules/x", bytes.NewBufferString(`{"cron":"@hourly","type":"echo"}`))
+	rr := httptest.NewRecorder()
+
+	h.UpdateSchedule(rr, withID(req, "x"))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetAndDeleteSchedule(t *testing.T) {
+	ms := &mockScheduleStore{schedules: map[string]*model.Schedule{
+		"s1": {ID: "s1", Cron: "@hourly", Timezone: "UTC", Type: "echo"},
+	}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithScheduleStore(ms))
+
+	req, _ := http.NewRequest("GET", "/schedules/s1", nil)
+	rr := httptest.NewRecorder()
+	h.GetSchedule(rr, withID(req, "s1"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req, _ = http.NewRequest("DELETE", "/schedules/s1", nil)
+	rr = httptest.NewRecorder()
+	h.DeleteSchedule(rr, withID(req, "s1"))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	req, _ = http.NewRequest("GET", "/schedules/s1", nil)
+	rr = httptest.NewRecorder()
+	h.GetSchedule(rr, withID(req, "s1"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type TaskTypeLister interface {
+	TaskTypes() []model.TaskType
+}
+
+type TypeDepthCounter interface {
+	PendingByType(ctx context.Context) (map[string]int64, error)
+}
+
+func WithTaskTypes(l TaskTypeLister, d TypeDepthCounter) Option {
+	return func(h *Handler) {
+		h.taskTypes = l
+		h.typeDepths = d
+	}
+}
+
+func (h *Handler) ListTaskTypes(w http.ResponseWriter, r *http.Request) {
+	if h.taskTypes == nil {
+		respondError(w, http.StatusNotImplemented, "task type registry is not configured")
+		return
+	}
+
+	types := h.taskTypes.TaskTypes()
+
+	if h.typeDepths != nil {
+		depths, err := h.typeDepths.PendingByType(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for i := range types {
+			types[i].QueueDepth = depths[types[i].Name]
+		}
+	}
+
+	if h.pauser != nil {
+		paused, err := h.pauser.PausedTypes(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for i := range types {
+			types[i].Paused = paused[types[i].Name]
+		}
+	}
+
+	respondJSON(w, http.StatusOK, types)
+}
+
+func (h *Handler) PauseTaskType(w http.ResponseWriter, r *http.Request) {
+	h.setTypePaused(w, r, true)
+}
+
+func (h *Handler) ResumeTaskType(w http.ResponseWriter, r *http.Request) {
+	h.setTypePaused(w, r, false)
+}
+
+// setTypePaused не проверяет, зарегистрирован ли тип: приостановить тип можно
+// и до того, как появятся обрабатывающие его пулы.
+func (h *Handler) setTypePaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if h.pauser == nil {
+		respondError(w, http.StatusNotImplemented, "pause controller is not configured")
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	set := h.pauser.ResumeType
+	if paused {
+		set = h.pauser.PauseType
+	}
+	if err := set(r.Context(), name); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, PauseResponse{Name: name, Paused: paused})
+}
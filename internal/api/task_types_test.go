@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTaskTypes []model.TaskType
+
+func (m mockTaskTypes) TaskTypes() []model.TaskType {
+	return append([]model.TaskType(nil), m...)
+}
+
+type mockDepths map[string]int64
+
+func (m mockDepths) PendingByType(ctx context.Context) (map[string]int64, error) {
+	return m, nil
+}
+
+func TestListTaskTypes(t *testing.T) {
+	types := mockTaskTypes{
+		{Name: "echo", Timeout: model.Duration(30 * time.Second), MaxRetry: 3},
+		{Name: "sum", Schema: json.RawMessage(`{"type":"array"}`), MaxRetry: 3},
+	}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithTaskTypes(types, mockDepths{"sum": 4}))
+
+	rr := httptest.NewRecorder()
+	h.ListTaskTypes(rr, httptest.NewRequest("GET", "/task-types", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res []model.TaskType
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	assert.Equal(t, "echo", res[0].Name)
+	assert.Equal(t, int64(0), res[0].QueueDepth)
+	assert.Equal(t, model.Duration(30*time.Second), res[0].Timeout)
+	assert.Equal(t, int64(4), res[1].QueueDepth)
+	assert.JSONEq(t, `{"type":"array"}`, string(res[1].Schema))
+}
+
+func TestListTaskTypes_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.ListTaskTypes(rr, httptest.NewRequest("GET", "/task-types", nil))
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 5 * time.Minute
+)
+
+// TaskWaiter ждет завершения задачи. По истечении ctx возвращается задача в
+// текущем статусе, для несуществующей — nil.
+type TaskWaiter interface {
+	WaitFinished(ctx context.Context, id string) (*model.Task, error)
+}
+
+func WithTaskWaiter(tw TaskWaiter) Option {
+	return func(h *Handler) {
+		h.waiter = tw
+	}
+}
+
+// WaitTask блокируется, пока задача не завершится или не истечет timeout
+// (по умолчанию 30s). Отвечает 200 с завершенной задачей и 202 с задачей в
+// текущем статусе, если дождаться не удалось.
+func (h *Handler) WaitTask(w http.ResponseWriter, r *http.Request) {
+	if h.waiter == nil {
+		respondError(w, http.StatusNotImplemented, "task waiting is not configured")
+		return
+	}
+
+	timeout := defaultWaitTimeout
+	if param := r.URL.Query().Get("timeout"); param != "" {
+		d, err := time.ParseDuration(param)
+		if err != nil || d <= 0 {
+			respondError(w, http.StatusBadRequest, "timeout must be a positive duration")
+			return
+		}
+		if d > maxWaitTimeout {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("timeout must not exceed %s", maxWaitTimeout))
+			return
+		}
+		timeout = d
+	}
+
+	// Ожидание может быть дольше WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(timeout + 10*time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	task, err := h.waiter.WaitFinished(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if task == nil {
+		respondError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	if !task.Status.Finished() {
+		respondJSON(w, http.StatusAccepted, task)
+		return
+	}
+	respondJSON(w, http.StatusOK, task)
+}
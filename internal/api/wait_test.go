@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWaiter struct {
+	tasks   map[string]*model.Task
+	timeout time.Duration
+}
+
+func (m *mockWaiter) WaitFinished(ctx context.Context, id string) (*model.Task, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		m.timeout = time.Until(deadline).Round(time.Second)
+	}
+	t, ok := m.tasks[id]
+	if !ok {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func waitRequest(id, query string) *http.Request {
+	req := httptest.NewRequest("GET", "/tasks/"+id+"/wait"+query, nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func TestWaitTask(t *testing.T) {
+	waiter := &mockWaiter{tasks: map[string]*model.Task{
+		"done":    {ID: "done", Status: model.StatusCompleted},
+		"running": {ID: "running", Status: model.StatusProcessing},
+	}}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithTaskWaiter(waiter))
+
+	tests := []struct {
+		name    string
+		id      string
+		query   string
+		code    int
+		timeout time.Duration
+	}{
+		{"finished", "done", "", http.StatusOK, defaultWaitTimeout},
+		{"timed out", "running", "?timeout=2s", http.StatusAccepted, 2 * time.Second},
+		{"missing", "missing", "", http.StatusNotFound, defaultWaitTimeout},
+		{"invalid timeout", "done", "?timeout=soon", http.StatusBadRequest, 0},
+		{"timeout too long", "done", "?timeout=1h", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waiter.timeout = 0
+			rr := httptest.NewRecorder()
+			h.WaitTask(rr, waitRequest(tt.id, tt.query))
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.timeout, waiter.timeout)
+		})
+	}
+}
+
+func TestWaitTask_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.WaitTask(rr, waitRequest("x", ""))
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+type WorkerRegistry interface {
+	ListWorkers(ctx context.Context) ([]model.WorkerInfo, error)
+	InFlight(ctx context.Context) ([]model.InFlightTask, error)
+}
+
+func WithWorkerRegistry(r WorkerRegistry) Option {
+	return func(h *Handler) {
+		h.workers = r
+	}
+}
+
+type WorkersResponse struct {
+	Workers  []model.WorkerInfo   `json:"workers"`
+	InFlight []model.InFlightTask `json:"in_flight"`
+}
+
+// ListWorkers возвращает живые воркеры и задачи с действующей арендой. Задача
+// без worker_id выполняется воркером, который перестал отправлять heartbeat.
+func (h *Handler) ListWorkers(w http.ResponseWriter, r *http.Request) {
+	if h.workers == nil {
+		respondError(w, http.StatusNotImplemented, "worker registry is not configured")
+		return
+	}
+
+	workers, err := h.workers.ListWorkers(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	inFlight, err := h.workers.InFlight(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	owners := make(map[string]string, len(workers))
+	for _, wk := range workers {
+		if wk.Task != nil {
+			owners[wk.Task.ID] = wk.ID
+		}
+	}
+	for i := range inFlight {
+		inFlight[i].WorkerID = owners[inFlight[i].TaskID]
+	}
+
+	respondJSON(w, http.StatusOK, WorkersResponse{Workers: workers, InFlight: inFlight})
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWorkerRegistry struct {
+	workers  []model.WorkerInfo
+	inFlight []model.InFlightTask
+}
+
+func (m *mockWorkerRegistry) ListWorkers(ctx context.Context) ([]model.WorkerInfo, error) {
+	return m.workers, nil
+}
+
+func (m *mockWorkerRegistry) InFlight(ctx context.Context) ([]model.InFlightTask, error) {
+	return m.inFlight, nil
+}
+
+func TestListWorkers(t *testing.T) {
+	reg := &mockWorkerRegistry{
+		workers: []model.WorkerInfo{
+			{ID: "host:1:0", Task: &model.WorkerTask{ID: "t1", Type: "slow"}},
+			{ID: "host:1:1"},
+		},
+		inFlight: []model.InFlightTask{
+			{TaskID: "t1", Type: "slow"},
+			{TaskID: "orphan", Type: "echo"},
+		},
+	}
+	h := NewHandler(&mockFullEnqueuer{}, nil, WithWorkerRegistry(reg))
+
+	rr := httptest.NewRecorder()
+	h.ListWorkers(rr, httptest.NewRequest("GET", "/workers", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res WorkersResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Len(t, res.Workers, 2)
+	require.Len(t, res.InFlight, 2)
+	assert.Equal(t, "host:1:0", res.InFlight[0].WorkerID)
+	assert.Empty(t, res.InFlight[1].WorkerID)
+}
+
+func TestListWorkers_NotConfigured(t *testing.T) {
+	h := NewHandler(&mockFullEnqueuer{}, nil)
+
+	rr := httptest.NewRecorder()
+	h.ListWorkers(rr, httptest.NewRequest("GET", "/workers", nil))
+
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +15,29 @@ type Config struct {
 	RedisDB     int
 	DBDsn       string
 	WorkerCount int
+
+	VisibilityTimeout time.Duration
+	PriorityWeights   map[string]int
+	IdempotencyWindow time.Duration
+
+	MaxTaskRetry    int
+	MaxTaskTimeout  time.Duration
+	MaxBackoffDelay time.Duration
+
+	SchemaDir string
+
+	Queues       []QueueWeight
+	StrictQueues bool
+
+	CallbackMaxAttempts     int
+	CallbackTimeout         time.Duration
+	CallbackAllowedNetworks []netip.Prefix
+}
+
+// QueueWeight — очередь, которую обслуживает пул, и ее вес.
+type QueueWeight struct {
+	Name   string
+	Weight int
 }
 
 func Load() *Config {
@@ -22,6 +48,23 @@ func Load() *Config {
 		RedisDB:     getEnvInt("REDIS_DB", 0),
 		DBDsn:       getEnv("DB_DSN", "host=localhost user=postgres password=postgres dbname=taskqueue sslmode=disable"),
 		WorkerCount: getEnvInt("WORKER_COUNT", 3),
+
+		VisibilityTimeout: getEnvDuration("VISIBILITY_TIMEOUT", time.Minute),
+		PriorityWeights:   getEnvWeights("PRIORITY_WEIGHTS"),
+		IdempotencyWindow: getEnvDuration("IDEMPOTENCY_WINDOW", 24*time.Hour),
+
+		MaxTaskRetry:    getEnvInt("MAX_TASK_RETRY", 10),
+		MaxTaskTimeout:  getEnvDuration("MAX_TASK_TIMEOUT", time.Hour),
+		MaxBackoffDelay: getEnvDuration("MAX_BACKOFF_DELAY", time.Hour),
+
+		SchemaDir: getEnv("SCHEMA_DIR", ""),
+
+		Queues:       getEnvQueues("QUEUES"),
+		StrictQueues: getEnvBool("STRICT_QUEUES", false),
+
+		CallbackMaxAttempts:     getEnvInt("CALLBACK_MAX_ATTEMPTS", 5),
+		CallbackTimeout:         getEnvDuration("CALLBACK_TIMEOUT", 10*time.Second),
+		CallbackAllowedNetworks: getEnvNetworks("CALLBACK_ALLOWED_NETWORKS"),
 	}
 }
 
@@ -40,3 +83,87 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+// getEnvWeights разбирает строку вида "high=6,default=3,low=1".
+func getEnvWeights(key string) map[string]int {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		if w, err := strconv.Atoi(value); err == nil && w > 0 {
+			weights[strings.TrimSpace(name)] = w
+		}
+	}
+	return weights
+}
+
+// getEnvQueues разбирает строку вида "critical=6,emails=3,default" с сохранением
+// порядка. Вес по умолчанию — 1.
+func getEnvQueues(key string) []QueueWeight {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+
+	var queues []QueueWeight
+	for _, item := range strings.Split(v, ",") {
+		name, value, hasWeight := strings.Cut(strings.TrimSpace(item), "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		weight := 1
+		if hasWeight {
+			if w, err := strconv.Atoi(value); err == nil && w > 0 {
+				weight = w
+			}
+		}
+		queues = append(queues, QueueWeight{Name: name, Weight: weight})
+	}
+	return queues
+}
+
+// getEnvNetworks разбирает список подсетей вида "10.0.0.0/8,fd00::/8".
+// Отдельный адрес считается подсетью из одного адреса.
+func getEnvNetworks(key string) []netip.Prefix {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+
+	var networks []netip.Prefix
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if p, err := netip.ParsePrefix(item); err == nil {
+			networks = append(networks, p.Masked())
+		} else if a, err := netip.ParseAddr(item); err == nil {
+			networks = append(networks, netip.PrefixFrom(a, a.BitLen()))
+		}
+	}
+	return networks
+}
@@ -10,6 +10,7 @@ import (
 type Metrics struct {
 	HTTPRequestTotal *prometheus.CounterVec
 	QueueDepth       *prometheus.GaugeVec
+	Paused           *prometheus.GaugeVec
 	TaskWaitDuration *prometheus.HistogramVec
 	ActiveWorkers    prometheus.Gauge
 	TasksProcessed   *prometheus.CounterVec
@@ -30,9 +31,16 @@ func NewMetrics() *Metrics {
 		QueueDepth: promauto.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "taskqueue_queue_depth",
-				Help: "Current depth of the task queue by priority",
+				Help: "Current depth of the task queue by queue and priority",
 			},
-			[]string{"priority"},
+			[]string{"queue", "priority"},
+		),
+		Paused: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "taskqueue_paused",
+				Help: "Whether a queue or task type is paused (1) or active (0)",
+			},
+			[]string{"kind", "name"},
 		),
 		TaskWaitDuration: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
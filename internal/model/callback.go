@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Callback — адрес, на который отправляется результат задачи после ее
+// завершения. Если задан Secret, запрос подписывается HMAC-SHA256.
+type Callback struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// CallbackAttempt — одна попытка доставки callback. StatusCode равен нулю,
+// если ответ не был получен.
+type CallbackAttempt struct {
+	Attempt    int       `json:"attempt"`
+	Event      EventType `json:"event"`
+	At         time.Time `json:"at"`
+	Duration   Duration  `json:"duration"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Delivered  bool      `json:"delivered"`
+}
+
+// CallbackDelivery — ожидающая доставка callback задачи. Attempt — число уже
+// сделанных попыток.
+type CallbackDelivery struct {
+	TaskID  string    `json:"task_id"`
+	Event   EventType `json:"event"`
+	Attempt int       `json:"attempt"`
+}
+
+// MarshalBinary позволяет сохранять доставку в Redis без ручной сериализации.
+func (d CallbackDelivery) MarshalBinary() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+// Redacted возвращает копию задачи без секрета callback, пригодную для
+// отправки клиентам.
+func (t *Task) Redacted() *Task {
+	if t == nil || t.Callback == nil || t.Callback.Secret == "" {
+		return t
+	}
+	c := *t
+	c.Callback = &Callback{URL: t.Callback.URL}
+	return &c
+}
@@ -0,0 +1,6 @@
+package model
+
+type DeadLetterFilter struct {
+	Type  string
+	Error string
+}
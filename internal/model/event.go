@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType — этап жизненного цикла задачи в потоке GET /events.
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventStarted   EventType = "started"
+	EventProgress  EventType = "progress"
+	EventRetried   EventType = "retried"
+	EventCompleted EventType = "completed"
+	EventFailed    EventType = "failed"
+	EventCancelled EventType = "cancelled"
+)
+
+// TaskEvent — событие жизненного цикла задачи. Progress заполнен только у
+// событий progress.
+type TaskEvent struct {
+	Event    EventType `json:"event"`
+	TaskID   string    `json:"task_id"`
+	TaskType string    `json:"type"`
+	Queue    string    `json:"queue"`
+	Status   Status    `json:"status"`
+	Retries  int       `json:"retries"`
+	Error    string    `json:"error,omitempty"`
+	Progress *Progress `json:"progress,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+func NewTaskEvent(event EventType, t *Task) TaskEvent {
+	return TaskEvent{
+		Event:    event,
+		TaskID:   t.ID,
+		TaskType: t.Type,
+		Queue:    t.QueueName(),
+		Status:   t.Status,
+		Retries:  t.Retries,
+		Error:    t.Error,
+		At:       time.Now(),
+	}
+}
+
+// MarshalBinary позволяет публиковать событие в Redis без ручной сериализации.
+func (e TaskEvent) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// EventFilter отбирает события для подписчика; пустые поля не ограничивают.
+type EventFilter struct {
+	TaskID   string
+	TaskType string
+	Status   Status
+}
+
+func (f EventFilter) Match(e TaskEvent) bool {
+	return (f.TaskID == "" || e.TaskID == f.TaskID) &&
+		(f.TaskType == "" || e.TaskType == f.TaskType) &&
+		(f.Status == "" || e.Status == f.Status)
+}
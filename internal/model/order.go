@@ -0,0 +1,31 @@
+package model
+
+import "math/rand/v2"
+
+// WeightedOrder возвращает элементы items в случайном порядке: каждый
+// следующий выбирается из оставшихся с вероятностью, пропорциональной весу.
+// Вес меньше 1 считается равным 1, чтобы ни один элемент не голодал.
+func WeightedOrder[T any](items []T, weight func(T) int) []T {
+	remaining := make([]T, len(items))
+	copy(remaining, items)
+	order := make([]T, 0, len(remaining))
+
+	for len(remaining) > 0 {
+		total := 0
+		for _, item := range remaining {
+			total += max(weight(item), 1)
+		}
+
+		n := rand.IntN(total)
+		for i, item := range remaining {
+			n -= max(weight(item), 1)
+			if n < 0 {
+				order = append(order, item)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedOrder(t *testing.T) {
+	weights := map[string]int{"heavy": 1000000, "light": 1, "zero": 0}
+	items := []string{"light", "zero", "heavy"}
+
+	order := WeightedOrder(items, func(s string) int { return weights[s] })
+
+	assert.ElementsMatch(t, items, order)
+	assert.Equal(t, "heavy", order[0])
+	assert.Equal(t, []string{"light", "zero", "heavy"}, items, "input must not be reordered")
+}
+
+func TestWeightedOrder_NonPositiveWeightsStillSelected(t *testing.T) {
+	seen := map[string]bool{}
+	for range 200 {
+		order := WeightedOrder([]string{"a", "b"}, func(string) int { return 0 })
+		seen[order[0]] = true
+	}
+	assert.True(t, seen["a"] && seen["b"])
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// RawPayload приводит сохраненный текст к JSON: валидный JSON остается как есть,
+// а строки из старых версий (например, "10,20,30") кодируются как JSON-строка.
+func RawPayload(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// DecodePayload разбирает payload задачи в v. Для совместимости со старыми
+// клиентами, передававшими JSON внутри строки, такая строка раскодируется
+// повторно.
+func (t *Task) DecodePayload(v any) error {
+	err := json.Unmarshal(t.Payload, v)
+	if err == nil {
+		return nil
+	}
+
+	var s string
+	if json.Unmarshal(t.Payload, &s) != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(s), v)
+}
+
+// PayloadString возвращает payload как текст: JSON-строку без кавычек, а
+// остальные значения — в исходном JSON-виде.
+func (t *Task) PayloadString() string {
+	var s string
+	if err := json.Unmarshal(t.Payload, &s); err == nil {
+		return s
+	}
+	return string(t.Payload)
+}
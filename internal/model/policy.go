@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+type BackoffStrategy string
+
+const (
+	BackoffExponential BackoffStrategy = "exponential"
+	BackoffLinear      BackoffStrategy = "linear"
+	BackoffFixed       BackoffStrategy = "fixed"
+	// BackoffCustom описывает тип с собственной функцией backoff и не может
+	// быть задан в задаче.
+	BackoffCustom BackoffStrategy = "custom"
+)
+
+const DefaultBackoffDelay = time.Second
+
+// Backoff описывает интервал между попытками: exponential — delay * 2^n,
+// linear — delay * (n+1), fixed — delay. MaxDelay ограничивает интервал сверху,
+// Jitter (от 0 до 1) случайно уменьшает его на эту долю.
+type Backoff struct {
+	Strategy BackoffStrategy `json:"strategy"`
+	Delay    Duration        `json:"delay,omitempty"`
+	MaxDelay Duration        `json:"max_delay,omitempty"`
+	Jitter   float64         `json:"jitter,omitempty"`
+}
+
+func (b Backoff) Validate() error {
+	switch b.Strategy {
+	case BackoffExponential, BackoffLinear, BackoffFixed:
+	default:
+		return fmt.Errorf("backoff strategy must be one of: exponential, linear, fixed")
+	}
+	if b.Delay < 0 || b.MaxDelay < 0 {
+		return fmt.Errorf("backoff delays must not be negative")
+	}
+	if b.Jitter < 0 || b.Jitter > 1 {
+		return fmt.Errorf("backoff jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// Next возвращает задержку перед повтором после retries неудачных попыток.
+func (b Backoff) Next(retries int) time.Duration {
+	delay := time.Duration(b.Delay)
+	if delay <= 0 {
+		delay = DefaultBackoffDelay
+	}
+
+	// Рост насыщается до сравнения с MaxDelay: переполнение int64 дало бы
+	// отрицательную задержку и немедленный повтор
+	switch b.Strategy {
+	case BackoffLinear:
+		if time.Duration(retries+1) > math.MaxInt64/delay {
+			delay = math.MaxInt64
+		} else {
+			delay *= time.Duration(retries + 1)
+		}
+	case BackoffFixed:
+	default:
+		for i := 0; i < retries && (b.MaxDelay <= 0 || delay < time.Duration(b.MaxDelay)); i++ {
+			if delay > math.MaxInt64/2 {
+				delay = math.MaxInt64
+				break
+			}
+			delay <<= 1
+		}
+	}
+
+	if b.MaxDelay > 0 && delay > time.Duration(b.MaxDelay) {
+		delay = time.Duration(b.MaxDelay)
+	}
+	if b.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * b.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// ExecutionPolicy хранит явно заданные при создании задачи переопределения
+// лимита попыток, таймаута обработки и стратегии backoff.
+type ExecutionPolicy struct {
+	MaxRetry *int     `json:"max_retry,omitempty"`
+	Timeout  Duration `json:"timeout,omitempty"`
+	Backoff  *Backoff `json:"backoff,omitempty"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Next(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		retries int
+		want    time.Duration
+	}{
+		{"exponential", Backoff{Strategy: BackoffExponential, Delay: Duration(time.Second)}, 3, 8 * time.Second},
+		{"linear", Backoff{Strategy: BackoffLinear, Delay: Duration(time.Second)}, 3, 4 * time.Second},
+		{"fixed", Backoff{Strategy: BackoffFixed, Delay: Duration(time.Second)}, 3, time.Second},
+		{"default delay", Backoff{Strategy: BackoffExponential}, 1, 2 * DefaultBackoffDelay},
+		{"max delay", Backoff{Strategy: BackoffExponential, Delay: Duration(10 * time.Second), MaxDelay: Duration(time.Hour)}, 40, time.Hour},
+		{"saturates without max delay", Backoff{Strategy: BackoffExponential, Delay: Duration(10 * time.Second)}, 40, math.MaxInt64},
+		{"linear saturates", Backoff{Strategy: BackoffLinear, Delay: Duration(time.Hour)}, math.MaxInt32, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.backoff.Next(tt.retries))
+		})
+	}
+}
+
+func TestBackoff_NextJitterStaysPositive(t *testing.T) {
+	b := Backoff{Strategy: BackoffExponential, Delay: Duration(10 * time.Second), MaxDelay: Duration(time.Hour), Jitter: 0.5}
+	for range 100 {
+		d := b.Next(40)
+		assert.Greater(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, time.Hour)
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Priority string
+
+const (
+	PriorityHigh    Priority = "high"
+	PriorityDefault Priority = "default"
+	PriorityLow     Priority = "low"
+)
+
+// Priorities перечислены от высшего к низшему.
+var Priorities = []Priority{PriorityHigh, PriorityDefault, PriorityLow}
+
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityHigh, PriorityDefault, PriorityLow:
+		return true
+	}
+	return false
+}
+
+// OrDefault нужен для задач, созданных до появления приоритетов.
+func (p Priority) OrDefault() Priority {
+	if p == "" {
+		return PriorityDefault
+	}
+	return p
+}
+
+// UnmarshalJSON принимает имя уровня ("high", "default", "low") или число:
+// положительное — high, ноль — default, отрицательное — low.
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var n float64
+	if err := json.Unmarshal(data, &n); err == nil {
+		switch {
+		case n > 0:
+			*p = PriorityHigh
+		case n < 0:
+			*p = PriorityLow
+		default:
+			*p = PriorityDefault
+		}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("priority must be a string or a number")
+	}
+	*p = Priority(strings.ToLower(s))
+	return nil
+}
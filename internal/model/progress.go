@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Progress — ход выполнения задачи, о котором сообщает обработчик.
+type Progress struct {
+	Percent   float64   `json:"percent"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
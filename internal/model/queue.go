@@ -0,0 +1,36 @@
+package model
+
+import "regexp"
+
+// DefaultQueue — очередь задач, для которых очередь не указана.
+const DefaultQueue = "default"
+
+var queueNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ValidQueueName проверяет имя очереди: латиница, цифры, "_", "-", "." и не
+// длиннее 64 символов. Двоеточие запрещено, оно разделяет части ключей Redis.
+func ValidQueueName(name string) bool {
+	return queueNameRe.MatchString(name)
+}
+
+// QueueName возвращает очередь задачи; задачи, созданные до появления
+// именованных очередей, относятся к DefaultQueue.
+func (t *Task) QueueName() string {
+	if t.Queue == "" {
+		return DefaultQueue
+	}
+	return t.Queue
+}
+
+// QueueStats — состояние очереди для GET /queues. Completed и Failed считаются
+// за последние Window, Throughput — среднее число завершенных задач в минуту
+// за то же окно.
+type QueueStats struct {
+	Name       string   `json:"name"`
+	Pending    int64    `json:"pending"`
+	Paused     bool     `json:"paused"`
+	Completed  int64    `json:"completed"`
+	Failed     int64    `json:"failed"`
+	Throughput float64  `json:"throughput_per_minute"`
+	Window     Duration `json:"window"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type Schedule struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Cron      string          `json:"cron"`
+	Timezone  string          `json:"timezone"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	Queue     string          `json:"queue"`
+	Priority  Priority        `json:"priority"`
+	Enabled   bool            `json:"enabled"`
+	NextRunAt time.Time       `json:"next_run_at"`
+	LastRunAt *time.Time      `json:"last_run_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// NextAfter вычисляет следующее срабатывание cron-выражения после t в часовом
+// поясе расписания. Поддерживается стандартный формат из пяти полей и
+// дескрипторы вида @hourly.
+func (s *Schedule) NextAfter(t time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+
+	sched, err := cron.ParseStandard(s.Cron)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %q: %w", s.Cron, err)
+	}
+
+	// Для выражения, которое никогда не срабатывает (например, 30 февраля),
+	// Next возвращает нулевое время: такое расписание было бы "наступившим" вечно
+	next := sched.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never fires", s.Cron)
+	}
+	return next, nil
+}
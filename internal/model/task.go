@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 )
 
@@ -8,22 +10,82 @@ type Status string
 
 const (
 	StatusPending    Status = "pending"
+	StatusScheduled  Status = "scheduled"
 	StatusProcessing Status = "processing"
 	StatusCompleted  Status = "completed"
 	StatusFailed     Status = "failed"
+	StatusCancelled  Status = "cancelled"
 )
 
+// Finished сообщает, что задача больше не будет выполняться.
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
 const DefaultMaxRetry = 3
 
+// ErrDuplicateRequest возвращается вместе с исходной задачей, если задача с тем же
+// ключом идемпотентности уже была создана.
+var ErrDuplicateRequest = errors.New("task with this idempotency key already exists")
+
+// ErrDuplicateTask возвращается вместе с уже существующей задачей, если
+// уникальная задача с тем же ключом еще ожидает выполнения или выполняется.
+var ErrDuplicateTask = errors.New("unique task already exists")
+
+// ErrTaskFinished возвращается вместе с задачей при попытке отменить уже
+// завершенную задачу.
+var ErrTaskFinished = errors.New("task already finished")
+
+// ErrLeaseLost возвращается при попытке подтвердить или изменить задачу,
+// которую уже выдали другому воркеру после истечения аренды.
+var ErrLeaseLost = errors.New("task lease lost")
+
+// ErrTaskCancelled — причина отмены контекста обработчика при отмене задачи.
+var ErrTaskCancelled = errors.New("task cancelled")
+
+type EnqueueOptions struct {
+	Queue          string
+	Priority       Priority
+	RunAt          time.Time
+	IdempotencyKey string
+
+	// Unique запрещает создавать задачу, пока не завершена другая с тем же
+	// UniqueKey (по умолчанию — хеш payload) в пределах типа.
+	Unique    bool
+	UniqueKey string
+	UniqueTTL time.Duration
+
+	Policy   *ExecutionPolicy
+	Callback *Callback
+}
+
 type Task struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	Payload   string    `json:"payload"`
-	Status    Status    `json:"status"`
-	Result    string    `json:"result,omitempty"`
-	Error     string    `json:"error,omitempty"`
-	Retries   int       `json:"retries"`
-	MaxRetry  int       `json:"max_retry"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Queue     string          `json:"queue,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    Status          `json:"status"`
+	Priority  Priority        `json:"priority"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Retries   int             `json:"retries"`
+	MaxRetry  int             `json:"max_retry"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+
+	RunAt       *time.Time `json:"run_at,omitempty"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	UniqueKey      string `json:"unique_key,omitempty"`
+
+	Policy   *ExecutionPolicy `json:"policy,omitempty"`
+	Callback *Callback        `json:"callback,omitempty"`
+
+	// DeliveryToken выдается Pop и не сохраняется: Ack, Nack, Retry и
+	// DeadLetter с устаревшим токеном отклоняются
+	DeliveryToken string `json:"-"`
+
+	// Progress хранится отдельно от задачи и заполняется только в ответах API
+	Progress *Progress `json:"progress,omitempty"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import "encoding/json"
+
+// TaskType описывает зарегистрированный в пуле тип задачи: его схему payload,
+// политику выполнения по умолчанию и текущую глубину очереди.
+type TaskType struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	Timeout     Duration        `json:"timeout"`
+	MaxRetry    int             `json:"max_retry"`
+	Backoff     Backoff         `json:"backoff"`
+	Concurrency int             `json:"concurrency,omitempty"`
+	QueueDepth  int64           `json:"queue_depth"`
+	Paused      bool            `json:"paused"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownTaskType возвращается при постановке задачи типа, который не
+// зарегистрирован ни одним обработчиком или схемой.
+var ErrUnknownTaskType = errors.New("unknown task type")
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError описывает несоответствие payload схеме его типа. Field —
+// JSON Pointer на поле payload ("" — весь payload).
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return "payload does not match schema"
+	}
+	return fmt.Sprintf("payload does not match schema: %s: %s", e.Fields[0].Field, e.Fields[0].Message)
+}
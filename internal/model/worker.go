@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// WorkerInfo — состояние воркера пула, которое он публикует в heartbeat.
+type WorkerInfo struct {
+	ID        string      `json:"id"`
+	Host      string      `json:"host"`
+	PID       int         `json:"pid"`
+	WorkerID  int         `json:"worker_id"`
+	Queues    []string    `json:"queues"`
+	StartedAt time.Time   `json:"started_at"`
+	LastSeen  time.Time   `json:"last_seen"`
+	Task      *WorkerTask `json:"task,omitempty"`
+
+	// Stalled — задача выполняется дольше своего таймаута, то есть обработчик
+	// не реагирует на отмену контекста.
+	Stalled bool `json:"stalled"`
+}
+
+// WorkerTask — задача, которую воркер выполняет сейчас.
+type WorkerTask struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	StartedAt time.Time `json:"started_at"`
+	Deadline  time.Time `json:"deadline"`
+}
+
+// InFlightTask — задача с действующей арендой. WorkerID пуст, если ни один
+// живой воркер не сообщает о ней: задача будет возвращена в очередь reaper'ом.
+type InFlightTask struct {
+	TaskID         string    `json:"task_id"`
+	Type           string    `json:"type"`
+	Queue          string    `json:"queue"`
+	WorkerID       string    `json:"worker_id,omitempty"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+}
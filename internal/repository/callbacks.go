@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	callbacksPrefix = "taskqueue:callbacks:"
+	// callbackDueKey — sorted set ожидающих доставок callback по времени
+	// следующей попытки; сами доставки лежат в хеше callbackDeliveriesKey.
+	callbackDueKey        = "taskqueue:callback-due"
+	callbackDeliveriesKey = "taskqueue:callback-deliveries"
+)
+
+// claimCallbacksScript выдает доставки, время которых наступило, и сдвигает
+// их время на ARGV[3]: пока доставка выполняется, другие реплики ее не возьмут,
+// а если обработчик пропадет, она снова станет доступной.
+var claimCallbacksScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+local deliveries = {}
+for _, id in ipairs(ids) do
+	local d = redis.call('HGET', KEYS[2], id)
+	if d then
+		redis.call('ZADD', KEYS[1], ARGV[3], id)
+		table.insert(deliveries, d)
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return deliveries
+`)
+
+// settleCallbackScript завершает доставку (ARGV[3] пуст) или откладывает ее
+// до ARGV[4], если она не изменилась с момента выдачи: задача могла
+// завершиться повторно и поставить новую доставку.
+var settleCallbackScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == '' then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	redis.call('HDEL', KEYS[2], ARGV[1])
+else
+	redis.call('HSET', KEYS[2], ARGV[1], ARGV[3])
+	redis.call('ZADD', KEYS[1], ARGV[4], ARGV[1])
+end
+return 1
+`)
+
+// enqueueCallback ставит доставку callback задачи в той же транзакции, что и
+// ее завершение: так доставка переживет перезапуск и не зависит от того, кто
+// завершил задачу — воркер или reaper.
+func enqueueCallback(ctx context.Context, pipe redis.Pipeliner, event model.EventType, t *model.Task) {
+	if t.Callback == nil || t.Callback.URL == "" {
+		return
+	}
+	pipe.HSet(ctx, callbackDeliveriesKey, t.ID, model.CallbackDelivery{TaskID: t.ID, Event: event})
+	pipe.ZAdd(ctx, callbackDueKey, redis.Z{Score: float64(time.Now().UnixMilli()), Member: t.ID})
+}
+
+// ClaimCallbacks возвращает до limit доставок callback, время которых
+// наступило, и откладывает их до until. Доставку нужно завершить через
+// FinishCallback или RetryCallback, иначе после until она будет выдана снова.
+func (q *RedisQueue) ClaimCallbacks(ctx context.Context, until time.Time, limit int) ([]model.CallbackDelivery, error) {
+	items, err := claimCallbacksScript.Run(ctx, q.client,
+		[]string{callbackDueKey, callbackDeliveriesKey},
+		time.Now().UnixMilli(), limit, until.UnixMilli(),
+	).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("claim callbacks: %w", err)
+	}
+
+	deliveries := make([]model.CallbackDelivery, 0, len(items))
+	for _, item := range items {
+		var d model.CallbackDelivery
+		if err := json.Unmarshal([]byte(item), &d); err != nil {
+			return nil, fmt.Errorf("unmarshal callback delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// FinishCallback удаляет выданную доставку: callback доставлен или попытки
+// исчерпаны.
+func (q *RedisQueue) FinishCallback(ctx context.Context, d model.CallbackDelivery) error {
+	return q.settleCallback(ctx, d, nil, time.Time{})
+}
+
+// RetryCallback засчитывает попытку выданной доставки и откладывает следующую
+// до at.
+func (q *RedisQueue) RetryCallback(ctx context.Context, d model.CallbackDelivery, at time.Time) error {
+	next := d
+	next.Attempt++
+	return q.settleCallback(ctx, d, &next, at)
+}
+
+func (q *RedisQueue) settleCallback(ctx context.Context, d model.CallbackDelivery, next *model.CallbackDelivery, at time.Time) error {
+	claimed, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("marshal callback delivery: %w", err)
+	}
+	var data []byte
+	if next != nil {
+		if data, err = json.Marshal(next); err != nil {
+			return fmt.Errorf("marshal callback delivery: %w", err)
+		}
+	}
+
+	err = settleCallbackScript.Run(ctx, q.client,
+		[]string{callbackDueKey, callbackDeliveriesKey},
+		d.TaskID, claimed, data, at.UnixMilli(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("settle callback: %w", err)
+	}
+	return nil
+}
+
+// RecordCallbackAttempt добавляет попытку доставки callback в журнал задачи.
+// Журнал живет столько же, сколько завершенная задача.
+func (q *RedisQueue) RecordCallbackAttempt(ctx context.Context, id string, a model.CallbackAttempt) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("marshal callback attempt: %w", err)
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.RPush(ctx, callbacksPrefix+id, data)
+	pipe.Expire(ctx, callbacksPrefix+id, 24*time.Hour)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("record callback attempt: %w", err)
+	}
+	return nil
+}
+
+// CallbackAttempts возвращает попытки доставки callback задачи по порядку.
+func (q *RedisQueue) CallbackAttempts(ctx context.Context, id string) ([]model.CallbackAttempt, error) {
+	items, err := q.client.LRange(ctx, callbacksPrefix+id, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list callback attempts: %w", err)
+	}
+
+	attempts := make([]model.CallbackAttempt, 0, len(items))
+	for _, item := range items {
+		var a model.CallbackAttempt
+		if err := json.Unmarshal([]byte(item), &a); err != nil {
+			return nil, fmt.Errorf("unmarshal callback attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_CallbackAttempts(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	callback := &model.Callback{URL: "https://example.com/hook", Secret: "s3cret"}
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Callback: callback})
+	require.NoError(t, err)
+
+	stored, err := q.Get(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, callback, stored.Callback)
+
+	require.NoError(t, q.RecordCallbackAttempt(ctx, task.ID, model.CallbackAttempt{Attempt: 1, StatusCode: 503}))
+	require.NoError(t, q.RecordCallbackAttempt(ctx, task.ID, model.CallbackAttempt{Attempt: 2, StatusCode: 200, Delivered: true}))
+
+	attempts, err := q.CallbackAttempts(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 1, attempts[0].Attempt)
+	assert.True(t, attempts[1].Delivered)
+
+	require.NoError(t, q.Delete(ctx, task.ID))
+	assert.False(t, mr.Exists(callbacksPrefix+task.ID))
+}
+
+func TestQueue_CallbackDeliveries(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	callback := &model.Callback{URL: "https://example.com/hook"}
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Callback: callback})
+	require.NoError(t, err)
+	_, err = q.Push(ctx, "echo", json.RawMessage(`"y"`), model.EnqueueOptions{})
+	require.NoError(t, err)
+
+	for range 2 {
+		popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+		require.NoError(t, err)
+		popped.Status = model.StatusCompleted
+		require.NoError(t, q.Update(ctx, popped))
+		require.NoError(t, q.Ack(ctx, popped))
+	}
+
+	// Доставка ставится только для задачи с callback
+	deliveries, err := q.ClaimCallbacks(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, []model.CallbackDelivery{{TaskID: task.ID, Event: model.EventCompleted}}, deliveries)
+
+	// Выданная доставка недоступна до истечения захвата
+	again, err := q.ClaimCallbacks(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	require.NoError(t, q.RetryCallback(ctx, deliveries[0], time.Now().Add(-time.Millisecond)))
+	retried, err := q.ClaimCallbacks(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+	assert.Equal(t, 1, retried[0].Attempt)
+
+	// Устаревшая доставка не снимает текущую
+	require.NoError(t, q.FinishCallback(ctx, deliveries[0]))
+	assert.True(t, mr.Exists(callbackDeliveriesKey))
+
+	require.NoError(t, q.FinishCallback(ctx, retried[0]))
+	assert.False(t, mr.Exists(callbackDeliveriesKey))
+	assert.False(t, mr.Exists(callbackDueKey))
+}
+
+func TestReaper_QueuesCallbackOfFailedTask(t *testing.T) {
+	q, mr := setupTestQueue(t, WithVisibilityTimeout(50*time.Millisecond))
+	defer mr.Close()
+	ctx := context.Background()
+
+	callback := &model.Callback{URL: "https://example.com/hook"}
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Callback: callback})
+	require.NoError(t, err)
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	popped.Retries = popped.MaxRetry
+	require.NoError(t, q.Update(ctx, popped))
+
+	time.Sleep(60 * time.Millisecond)
+	n, err := q.ReapExpired(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	deliveries, err := q.ClaimCallbacks(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.CallbackDelivery{{TaskID: task.ID, Event: model.EventFailed}}, deliveries)
+}
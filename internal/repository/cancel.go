@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	cancelChannel = "taskqueue:cancel"
+	cancelPrefix  = "taskqueue:cancel:"
+)
+
+// cancelAttempts ограничивает число попыток отмены задачи, которую параллельно
+// меняют воркер или reaper.
+const cancelAttempts = 3
+
+// cancelScript сверяет сохраненную задачу с прочитанной в ARGV[5] и, если она
+// не изменилась, убирает ее из списка pending и отложенных множеств и сохраняет
+// отмененной. Если задачи там нет, но она выполняется, ставится отметка об
+// отмене и воркеры оповещаются через pub/sub: после потери аренды задачу
+// отменит воркер, который возьмет ее повторно.
+var cancelScript = redis.NewScript(`
+local stored = redis.call('GET', KEYS[1])
+if not stored then
+	return 'missing'
+end
+if stored ~= ARGV[5] then
+	return 'changed'
+end
+local removed = redis.call('LREM', KEYS[2], 0, ARGV[1])
+	+ redis.call('ZREM', KEYS[3], ARGV[1])
+	+ redis.call('ZREM', KEYS[4], ARGV[1])
+if removed == 0 and (redis.call('ZSCORE', KEYS[5], ARGV[1]) or ARGV[6] == '1') then
+	redis.call('SET', KEYS[6], '1', 'PX', ARGV[3])
+	redis.call('PUBLISH', ARGV[4], ARGV[1])
+	return 'requested'
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 'cancelled'
+`)
+
+// Cancel отменяет задачу. Ожидающая задача сразу получает статус cancelled, а
+// для выполняющейся запрашивается отмена у владеющего ею воркера; в этом случае
+// возвращается задача в прежнем статусе. Для завершенной задачи возвращается
+// model.ErrTaskFinished, для несуществующей — nil.
+func (q *RedisQueue) Cancel(ctx context.Context, id string) (*model.Task, error) {
+	for range cancelAttempts {
+		raw, err := q.client.Get(ctx, taskPrefix+id).Bytes()
+		if err != nil {
+			if err == redis.Nil {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("get task: %w", err)
+		}
+
+		var t model.Task
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, fmt.Errorf("unmarshal task: %w", err)
+		}
+		if t.Status.Finished() {
+			return &t, model.ErrTaskFinished
+		}
+
+		cancelled := t
+		cancelled.Status = model.StatusCancelled
+		cancelled.NextRetryAt = nil
+		cancelled.UpdatedAt = time.Now()
+
+		data, err := json.Marshal(&cancelled)
+		if err != nil {
+			return nil, fmt.Errorf("marshal task: %w", err)
+		}
+
+		running := "0"
+		if t.Status == model.StatusProcessing {
+			running = "1"
+		}
+
+		outcome, err := cancelScript.Run(ctx, q.client,
+			[]string{taskPrefix + id, taskPendingKey(&t), retryKey, scheduledKey, leaseKey, cancelPrefix + id},
+			id, data, (24 * time.Hour).Milliseconds(), cancelChannel, raw, running,
+		).Text()
+		if err != nil {
+			return nil, fmt.Errorf("cancel task: %w", err)
+		}
+
+		switch outcome {
+		case "missing":
+			return nil, nil
+		case "changed":
+			// Задачу изменили между чтением и отменой: перечитываем
+			continue
+		case "requested":
+			return &t, nil
+		}
+
+		if err := q.client.Publish(ctx, finishedChannelPrefix+id, id).Err(); err != nil {
+			return nil, fmt.Errorf("publish task finished: %w", err)
+		}
+		q.notify(ctx, model.EventCancelled, &cancelled)
+		if err := q.releaseUnique(ctx, &cancelled); err != nil {
+			return nil, err
+		}
+		return &cancelled, nil
+	}
+
+	return nil, fmt.Errorf("cancel task: task kept changing after %d attempts", cancelAttempts)
+}
+
+// AckCancelled сохраняет выполнявшуюся задачу отмененной и снимает аренду.
+func (q *RedisQueue) AckCancelled(ctx context.Context, t *model.Task) error {
+	t.Status = model.StatusCancelled
+	t.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal task: %w", err)
+	}
+
+	err = q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+		pipe.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour)
+		pipe.LRem(ctx, processingKey, 1, t.ID)
+		pipe.ZRem(ctx, leaseKey, t.ID)
+		pipe.Del(ctx, leaseTokenPrefix+t.ID, cancelPrefix+t.ID)
+		publishFinished(ctx, pipe, t.ID)
+		publishEvent(ctx, pipe, model.EventCancelled, t)
+	})
+	if err != nil {
+		return fmt.Errorf("ack cancelled task: %w", err)
+	}
+
+	return q.releaseUnique(ctx, t)
+}
+
+// CancelRequested сообщает, запрошена ли отмена задачи. Воркер проверяет это,
+// взяв задачу, на случай если оповещение пришло раньше, чем он начал ее слушать.
+func (q *RedisQueue) CancelRequested(ctx context.Context, id string) (bool, error) {
+	n, err := q.client.Exists(ctx, cancelPrefix+id).Result()
+	if err != nil {
+		return false, fmt.Errorf("check cancellation: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Cancellations возвращает ID задач, отмену которых запросили, пока ctx не
+// отменен.
+func (q *RedisQueue) Cancellations(ctx context.Context) <-chan string {
+	sub := q.client.Subscribe(ctx, cancelChannel)
+	ids := make(chan string)
+
+	go func() {
+		defer close(ids)
+		defer sub.Close()
+
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				select {
+				case ids <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ids
+}
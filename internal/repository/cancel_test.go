@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_CancelPending(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, err := q.Push(ctx, "reindex", json.RawMessage(`{}`), model.EnqueueOptions{Unique: true})
+	require.NoError(t, err)
+
+	cancelled, err := q.Cancel(ctx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, cancelled)
+	assert.Equal(t, model.StatusCancelled, cancelled.Status)
+
+	assert.False(t, mr.Exists(pendingKey(model.DefaultQueue, model.PriorityDefault, "reindex")))
+	stored, _ := q.Get(ctx, task.ID)
+	assert.Equal(t, model.StatusCancelled, stored.Status)
+
+	// Блокировка уникальности снята, задачу можно поставить снова
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`{}`), model.EnqueueOptions{Unique: true})
+	assert.NoError(t, err)
+
+	_, err = q.Cancel(ctx, task.ID)
+	assert.ErrorIs(t, err, model.ErrTaskFinished)
+}
+
+func TestQueue_CancelScheduled(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"later"`), model.EnqueueOptions{RunAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	cancelled, err := q.Cancel(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusCancelled, cancelled.Status)
+
+	assert.False(t, mr.Exists(scheduledKey))
+}
+
+func TestQueue_CancelRunning(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ids := q.Cancellations(ctx)
+	// Подписка оформляется асинхронно
+	time.Sleep(50 * time.Millisecond)
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+
+	res, err := q.Cancel(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPending, res.Status)
+
+	select {
+	case id := <-ids:
+		assert.Equal(t, task.ID, id)
+	case <-time.After(time.Second):
+		t.Fatal("cancellation was not published")
+	}
+
+	requested, err := q.CancelRequested(ctx, task.ID)
+	require.NoError(t, err)
+	assert.True(t, requested)
+
+	require.NoError(t, q.AckCancelled(ctx, popped))
+
+	stored, _ := q.Get(ctx, task.ID)
+	assert.Equal(t, model.StatusCancelled, stored.Status)
+	processing, _ := mr.List(processingKey)
+	assert.Empty(t, processing)
+	requested, _ = q.CancelRequested(ctx, task.ID)
+	assert.False(t, requested)
+}
+
+func TestQueue_CancelMissing(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+
+	task, err := q.Cancel(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Nil(t, task)
+}
+
+func TestQueue_DeleteRemovesPending(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	require.NoError(t, q.Delete(ctx, task.ID))
+
+	assert.False(t, mr.Exists(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo")))
+}
+
+func TestQueue_CancelReapedRunning(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	popped.Status = model.StatusProcessing
+	require.NoError(t, q.Update(ctx, popped))
+
+	// Reaper уже снял аренду, но еще не вернул задачу в очередь
+	_, err = mr.ZRem(leaseKey, task.ID)
+	require.NoError(t, err)
+
+	res, err := q.Cancel(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusProcessing, res.Status)
+
+	stored, _ := q.Get(ctx, task.ID)
+	assert.Equal(t, model.StatusProcessing, stored.Status)
+	requested, err := q.CancelRequested(ctx, task.ID)
+	require.NoError(t, err)
+	assert.True(t, requested)
+}
+
+func TestQueue_CancelSkipsChangedTask(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	stale, err := json.Marshal(task)
+	require.NoError(t, err)
+
+	// Задача завершилась после того, как Cancel ее прочитал
+	task.Status = model.StatusCompleted
+	require.NoError(t, q.Update(ctx, task))
+
+	outcome, err := cancelScript.Run(ctx, q.client,
+		[]string{taskPrefix + task.ID, taskPendingKey(task), retryKey, scheduledKey, leaseKey, cancelPrefix + task.ID},
+		task.ID, `{}`, time.Hour.Milliseconds(), cancelChannel, stale, "0",
+	).Text()
+	require.NoError(t, err)
+	assert.Equal(t, "changed", outcome)
+
+	stored, _ := q.Get(ctx, task.ID)
+	assert.Equal(t, model.StatusCompleted, stored.Status)
+
+	_, err = q.Cancel(ctx, task.ID)
+	assert.ErrorIs(t, err, model.ErrTaskFinished)
+}
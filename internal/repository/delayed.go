@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const promoteBatchSize = 100
+
+// promoteScript переносит задачу из sorted set в ее список pending и сохраняет
+// ее в статусе pending. ZREM служит захватом, поэтому при нескольких репликах
+// задача будет перенесена один раз.
+var promoteScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	redis.call('SET', KEYS[3], ARGV[2], 'EX', ARGV[3])
+	redis.call('RPUSH', KEYS[2], ARGV[1])
+	redis.call('PUBLISH', ARGV[4], ARGV[5])
+	return 1
+end
+return 0
+`)
+
+func (q *RedisQueue) promoteDue(ctx context.Context, key string, now time.Time) (int, error) {
+	total := 0
+	for {
+		ids, err := q.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   fmt.Sprint(now.UnixMilli()),
+			Count: promoteBatchSize,
+		}).Result()
+		if err != nil {
+			return total, fmt.Errorf("find due tasks: %w", err)
+		}
+
+		for _, id := range ids {
+			t, err := q.Get(ctx, id)
+			if err != nil {
+				return total, err
+			}
+			if t == nil {
+				q.client.ZRem(ctx, key, id)
+				continue
+			}
+
+			t.Status = model.StatusPending
+			t.NextRetryAt = nil
+			t.UpdatedAt = now
+			data, err := json.Marshal(t)
+			if err != nil {
+				return total, fmt.Errorf("marshal task: %w", err)
+			}
+
+			n, err := promoteScript.Run(ctx, q.client,
+				[]string{key, taskPendingKey(t), taskPrefix + id},
+				id, data, int((24 * time.Hour).Seconds()), wakeupChannel, t.QueueName(),
+			).Int()
+			if err != nil {
+				return total, fmt.Errorf("promote task: %w", err)
+			}
+			total += n
+		}
+
+		if len(ids) < promoteBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// PromoteDue возвращает в pending отложенные и ожидающие повтора задачи,
+// время запуска которых наступило.
+func (q *RedisQueue) PromoteDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	total := 0
+	for _, key := range []string{scheduledKey, retryKey} {
+		n, err := q.promoteDue(ctx, key, now)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (q *RedisQueue) StartPromoter(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := q.PromoteDue(ctx); err != nil && ctx.Err() == nil {
+					slog.Error("Promoter error", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// ListRetries возвращает задачи, ожидающие повтора, в порядке времени запуска.
+func (q *RedisQueue) ListRetries(ctx context.Context) ([]*model.Task, error) {
+	ids, err := q.client.ZRange(ctx, retryKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list retries: %w", err)
+	}
+	return q.getMany(ctx, ids)
+}
+
+func (q *RedisQueue) getMany(ctx context.Context, ids []string) ([]*model.Task, error) {
+	tasks := make([]*model.Task, 0, len(ids))
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = taskPrefix + id
+	}
+
+	values, err := q.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fetch tasks: %w", err)
+	}
+
+	for _, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		var t model.Task
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			continue
+		}
+		tasks = append(tasks, &t)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_RetryKeepsTaskUntilDue(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+
+	require.NoError(t, q.Retry(ctx, popped, 48*time.Hour))
+
+	// Ключ задачи переживает ожидание повтора
+	assert.Greater(t, mr.TTL(taskPrefix+tsk.ID), 48*time.Hour)
+	mr.FastForward(48*time.Hour + time.Minute)
+	assert.True(t, mr.Exists(taskPrefix+tsk.ID))
+}
+
+func TestQueue_RetryWithDelay(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+
+	err = q.Retry(ctx, popped, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	assert.False(t, mr.Exists(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo")))
+	assert.False(t, mr.Exists(processingKey))
+	assert.False(t, mr.Exists(leaseKey))
+
+	retries, err := q.ListRetries(ctx)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	assert.Equal(t, tsk.ID, retries[0].ID)
+	assert.Equal(t, 1, retries[0].Retries)
+	require.NotNil(t, retries[0].NextRetryAt)
+
+	n, err := q.PromoteDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	time.Sleep(60 * time.Millisecond)
+
+	n, err = q.PromoteDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{tsk.ID}, pending)
+	assert.False(t, mr.Exists(retryKey))
+}
+
+func TestQueue_ListRetries_Empty(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+
+	retries, err := q.ListRetries(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, retries)
+}
+
+func TestQueue_PushScheduled(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	runAt := time.Now().Add(50 * time.Millisecond)
+	tsk, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{Priority: model.PriorityHigh, RunAt: runAt})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusScheduled, tsk.Status)
+	require.NotNil(t, tsk.RunAt)
+
+	assert.False(t, mr.Exists(pendingKey(model.DefaultQueue, model.PriorityHigh, "echo")))
+	assert.True(t, mr.Exists(scheduledKey))
+
+	popped, err := q.Pop(ctx, 10*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	assert.Nil(t, popped)
+
+	time.Sleep(60 * time.Millisecond)
+
+	n, err := q.PromoteDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := q.Get(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPending, stored.Status)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityHigh, "echo"))
+	assert.Equal(t, []string{tsk.ID}, pending)
+}
+
+func TestQueue_PushScheduled_KeyOutlivesRunAt(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+
+	tsk, err := q.Push(context.Background(), "echo", json.RawMessage(`"data"`), model.EnqueueOptions{RunAt: time.Now().Add(48 * time.Hour)})
+	require.NoError(t, err)
+
+	assert.Greater(t, mr.TTL(taskPrefix+tsk.ID), 72*time.Hour-time.Minute)
+}
+
+func TestQueue_PushRunAtInPast(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+
+	tsk, err := q.Push(context.Background(), "echo", json.RawMessage(`"data"`), model.EnqueueOptions{RunAt: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPending, tsk.Status)
+	assert.Nil(t, tsk.RunAt)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{tsk.ID}, pending)
+}
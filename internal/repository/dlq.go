@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const dlqKey = "taskqueue:dlq"
+
+// requeueAttempts ограничивает число попыток вернуть из DLQ задачу, которую
+// параллельно меняют.
+const requeueAttempts = 3
+
+// purgeDeadLettersScript удаляет задачи из DLQ вместе с их ключами.
+var purgeDeadLettersScript = redis.NewScript(`
+local ids = redis.call('LRANGE', KEYS[1], 0, -1)
+for _, id in ipairs(ids) do
+	redis.call('DEL', ARGV[1] .. id)
+end
+redis.call('DEL', KEYS[1])
+return #ids
+`)
+
+// DeadLetter сохраняет упавшую задачу без TTL, снимает аренду и кладет ID в DLQ.
+func (q *RedisQueue) DeadLetter(ctx context.Context, t *model.Task) error {
+	t.Status = model.StatusFailed
+	t.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal task: %w", err)
+	}
+
+	err = q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+		pipe.Set(ctx, taskPrefix+t.ID, data, 0)
+		pipe.LRem(ctx, processingKey, 1, t.ID)
+		pipe.ZRem(ctx, leaseKey, t.ID)
+		pipe.Del(ctx, leaseTokenPrefix+t.ID)
+		pipe.LRem(ctx, dlqKey, 0, t.ID)
+		pipe.LPush(ctx, dlqKey, t.ID)
+		countProcessed(ctx, pipe, t.QueueName(), model.StatusFailed)
+		publishFinished(ctx, pipe, t.ID)
+		publishEvent(ctx, pipe, model.EventFailed, t)
+		enqueueCallback(ctx, pipe, model.EventFailed, t)
+	})
+	if err != nil {
+		return fmt.Errorf("dead letter task: %w", err)
+	}
+
+	return q.releaseUnique(ctx, t)
+}
+
+// ListDeadLetters возвращает задачи из DLQ (сначала новые). Error фильтра
+// сравнивается как подстрока без учета регистра.
+func (q *RedisQueue) ListDeadLetters(ctx context.Context, f model.DeadLetterFilter) ([]*model.Task, error) {
+	ids, err := q.client.LRange(ctx, dlqKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list dead letters: %w", err)
+	}
+
+	tasks, err := q.getMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	errSubstr := strings.ToLower(f.Error)
+	filtered := make([]*model.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if f.Type != "" && t.Type != f.Type {
+			continue
+		}
+		if errSubstr != "" && !strings.Contains(strings.ToLower(t.Error), errSubstr) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	return filtered, nil
+}
+
+// requeueDeadLetterScript сверяет сохраненную задачу с прочитанной в ARGV[2],
+// забирает блокировку уникальности и переносит задачу из DLQ в pending. Если
+// блокировку держит другая живая задача, задача остается в DLQ.
+var requeueDeadLetterScript = redis.NewScript(`
+local stored = redis.call('GET', KEYS[2])
+if not stored then
+	redis.call('LREM', KEYS[1], 1, ARGV[1])
+	return {'', 'missing'}
+end
+if stored ~= ARGV[2] then
+	return {'', 'changed'}
+end
+if KEYS[4] ~= '' then
+	local holder = redis.call('GET', KEYS[4])
+	if holder and holder ~= ARGV[1] and redis.call('EXISTS', ARGV[5] .. holder) == 1 then
+		return {holder, 'conflict'}
+	end
+end
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	return {'', 'missing'}
+end
+if KEYS[4] ~= '' then
+	redis.call('SET', KEYS[4], ARGV[1], 'PX', ARGV[4])
+end
+redis.call('SET', KEYS[2], ARGV[3], 'PX', ARGV[4])
+redis.call('RPUSH', KEYS[3], ARGV[1])
+redis.call('PUBLISH', ARGV[6], ARGV[7])
+redis.call('PUBLISH', ARGV[8], ARGV[1])
+return {ARGV[1], 'requeued'}
+`)
+
+// RequeueDeadLetter сбрасывает счетчик попыток и возвращает задачу из DLQ в pending.
+// Если задачи нет в DLQ, возвращает nil. Если за время нахождения в DLQ
+// создана другая задача с тем же ключом уникальности, задача остается в DLQ и
+// возвращается model.ErrDuplicateTask вместе с этой задачей.
+func (q *RedisQueue) RequeueDeadLetter(ctx context.Context, id string) (*model.Task, error) {
+	for range requeueAttempts {
+		raw, err := q.client.Get(ctx, taskPrefix+id).Bytes()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("get task: %w", err)
+		}
+
+		var t model.Task
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &t); err != nil {
+				return nil, fmt.Errorf("unmarshal task: %w", err)
+			}
+		}
+
+		t.Status = model.StatusPending
+		t.Retries = 0
+		t.Error = ""
+		t.Result = nil
+		t.NextRetryAt = nil
+		t.UpdatedAt = time.Now()
+
+		data, err := json.Marshal(&t)
+		if err != nil {
+			return nil, fmt.Errorf("marshal task: %w", err)
+		}
+		event, err := model.NewTaskEvent(model.EventRetried, &t).MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("marshal task event: %w", err)
+		}
+
+		uniqKey := ""
+		if t.UniqueKey != "" {
+			uniqKey = uniquePrefix + t.UniqueKey
+		}
+
+		res, err := requeueDeadLetterScript.Run(ctx, q.client,
+			[]string{dlqKey, taskPrefix + id, taskPendingKey(&t), uniqKey},
+			id, raw, data, (24 * time.Hour).Milliseconds(), taskPrefix, eventsChannel, event, wakeupChannel,
+		).StringSlice()
+		if err != nil {
+			return nil, fmt.Errorf("requeue dead letter: %w", err)
+		}
+
+		switch res[1] {
+		case "missing":
+			return nil, nil
+		case "changed":
+			continue
+		case "conflict":
+			holder, err := q.Get(ctx, res[0])
+			if err != nil {
+				return nil, err
+			}
+			if holder == nil {
+				// Владелец ключа успел завершиться: ключ свободен
+				continue
+			}
+			return holder, model.ErrDuplicateTask
+		}
+		return &t, nil
+	}
+
+	return nil, fmt.Errorf("requeue dead letter: task kept changing after %d attempts", requeueAttempts)
+}
+
+// RequeueDeadLettersByType возвращает в pending задачи типа taskType из DLQ.
+// Задачи, ключ уникальности которых занят другой задачей, остаются в DLQ.
+func (q *RedisQueue) RequeueDeadLettersByType(ctx context.Context, taskType string) (int, error) {
+	tasks, err := q.ListDeadLetters(ctx, model.DeadLetterFilter{Type: taskType})
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, t := range tasks {
+		rt, err := q.RequeueDeadLetter(ctx, t.ID)
+		if errors.Is(err, model.ErrDuplicateTask) {
+			continue
+		}
+		if err != nil {
+			return requeued, err
+		}
+		if rt != nil {
+			requeued++
+		}
+	}
+
+	return requeued, nil
+}
+
+func (q *RedisQueue) PurgeDeadLetters(ctx context.Context) (int, error) {
+	n, err := purgeDeadLettersScript.Run(ctx, q.client, []string{dlqKey}, taskPrefix).Int()
+	if err != nil {
+		return 0, fmt.Errorf("purge dead letters: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deadLetterTask(t *testing.T, q *RedisQueue, taskType, errMsg string) *model.Task {
+	ctx := context.Background()
+	tsk, err := q.Push(ctx, taskType, json.RawMessage(`"data"`), model.EnqueueOptions{})
+	require.NoError(t, err)
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, []string{taskType})
+	require.NoError(t, err)
+	popped.Retries = popped.MaxRetry
+	popped.Error = errMsg
+	require.NoError(t, q.DeadLetter(ctx, popped))
+	return tsk
+}
+
+func TestDLQ_DeadLetter(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk := deadLetterTask(t, q, "sum", "invalid payload")
+
+	assert.False(t, mr.Exists(processingKey))
+	assert.False(t, mr.Exists(leaseKey))
+	assert.Equal(t, time.Duration(0), mr.TTL(taskPrefix+tsk.ID))
+
+	stored, err := q.Get(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusFailed, stored.Status)
+}
+
+func TestDLQ_ListWithFilter(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	sum := deadLetterTask(t, q, "sum", "invalid payload")
+	deadLetterTask(t, q, "flaky", "random failure")
+	deadLetterTask(t, q, "sum", "timeout")
+
+	all, err := q.ListDeadLetters(ctx, model.DeadLetterFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	byType, err := q.ListDeadLetters(ctx, model.DeadLetterFilter{Type: "sum"})
+	require.NoError(t, err)
+	assert.Len(t, byType, 2)
+
+	byTypeAndError, err := q.ListDeadLetters(ctx, model.DeadLetterFilter{Type: "sum", Error: "INVALID"})
+	require.NoError(t, err)
+	require.Len(t, byTypeAndError, 1)
+	assert.Equal(t, sum.ID, byTypeAndError[0].ID)
+}
+
+func TestDLQ_Requeue(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk := deadLetterTask(t, q, "sum", "invalid payload")
+
+	requeued, err := q.RequeueDeadLetter(ctx, tsk.ID)
+	require.NoError(t, err)
+	require.NotNil(t, requeued)
+	assert.Equal(t, model.StatusPending, requeued.Status)
+	assert.Equal(t, 0, requeued.Retries)
+	assert.Empty(t, requeued.Error)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "sum"))
+	assert.Equal(t, []string{tsk.ID}, pending)
+	assert.False(t, mr.Exists(dlqKey))
+	assert.True(t, mr.TTL(taskPrefix+tsk.ID) > 0)
+
+	again, err := q.RequeueDeadLetter(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Nil(t, again)
+}
+
+func TestDLQ_RequeueUnique(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	opts := model.EnqueueOptions{Unique: true, UniqueKey: "customer-1"}
+	tsk, err := q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	require.NoError(t, err)
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, []string{"reindex"})
+	require.NoError(t, err)
+	require.NoError(t, q.DeadLetter(ctx, popped))
+
+	requeued, err := q.RequeueDeadLetter(ctx, tsk.ID)
+	require.NoError(t, err)
+	require.NotNil(t, requeued)
+
+	// Возвращенная задача снова держит ключ уникальности
+	holder, _ := mr.Get(uniquePrefix + requeued.UniqueKey)
+	assert.Equal(t, tsk.ID, holder)
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`"b"`), opts)
+	assert.ErrorIs(t, err, model.ErrDuplicateTask)
+}
+
+func TestDLQ_RequeueUniqueConflict(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	opts := model.EnqueueOptions{Unique: true, UniqueKey: "customer-1"}
+	failed, err := q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	require.NoError(t, err)
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, []string{"reindex"})
+	require.NoError(t, err)
+	require.NoError(t, q.DeadLetter(ctx, popped))
+
+	active, err := q.Push(ctx, "reindex", json.RawMessage(`"b"`), opts)
+	require.NoError(t, err)
+
+	holder, err := q.RequeueDeadLetter(ctx, failed.ID)
+	assert.ErrorIs(t, err, model.ErrDuplicateTask)
+	require.NotNil(t, holder)
+	assert.Equal(t, active.ID, holder.ID)
+
+	dlq, _ := mr.List(dlqKey)
+	assert.Equal(t, []string{failed.ID}, dlq)
+	stored, _ := q.Get(ctx, failed.ID)
+	assert.Equal(t, model.StatusFailed, stored.Status)
+
+	n, err := q.RequeueDeadLettersByType(ctx, "reindex")
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestDLQ_RequeueByType(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	deadLetterTask(t, q, "sum", "e1")
+	deadLetterTask(t, q, "sum", "e2")
+	flaky := deadLetterTask(t, q, "flaky", "e3")
+
+	n, err := q.RequeueDeadLettersByType(ctx, "sum")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	dlq, _ := mr.List(dlqKey)
+	assert.Equal(t, []string{flaky.ID}, dlq)
+}
+
+func TestDLQ_Purge(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk := deadLetterTask(t, q, "sum", "e1")
+	deadLetterTask(t, q, "flaky", "e2")
+
+	n, err := q.PurgeDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.False(t, mr.Exists(dlqKey))
+	stored, err := q.Get(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
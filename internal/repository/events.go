@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const eventsChannel = "taskqueue:events"
+
+// publishEvent добавляет событие задачи в транзакцию, меняющую ее состояние,
+// чтобы подписчики не увидели событие без изменения или наоборот.
+func publishEvent(ctx context.Context, pipe redis.Pipeliner, event model.EventType, t *model.Task) {
+	pipe.Publish(ctx, eventsChannel, model.NewTaskEvent(event, t))
+}
+
+// PublishEvent публикует событие жизненного цикла задачи.
+func (q *RedisQueue) PublishEvent(ctx context.Context, e model.TaskEvent) error {
+	if err := q.client.Publish(ctx, eventsChannel, e).Err(); err != nil {
+		return fmt.Errorf("publish task event: %w", err)
+	}
+	return nil
+}
+
+// notify публикует событие после операции, которая уже выполнена: ошибка
+// публикации не должна ее отменять, поэтому только логируется.
+func (q *RedisQueue) notify(ctx context.Context, event model.EventType, t *model.Task) {
+	if err := q.PublishEvent(ctx, model.NewTaskEvent(event, t)); err != nil {
+		slog.Warn("Failed to publish task event", "task_id", t.ID, "event", event, "error", err)
+	}
+}
+
+// SubscribeEvents возвращает события жизненного цикла задач, пока ctx не
+// отменен.
+func (q *RedisQueue) SubscribeEvents(ctx context.Context) <-chan model.TaskEvent {
+	msgs := q.listen(ctx, eventsChannel)
+	events := make(chan model.TaskEvent)
+
+	go func() {
+		defer close(events)
+
+		for payload := range msgs {
+			var e model.TaskEvent
+			if err := json.Unmarshal([]byte(payload), &e); err != nil {
+				continue
+			}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
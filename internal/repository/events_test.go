@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Events(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := q.SubscribeEvents(ctx)
+	// Подписка оформляется асинхронно
+	time.Sleep(50 * time.Millisecond)
+
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Queue: "emails"})
+	require.NoError(t, err)
+	popped, err := q.Pop(ctx, 100*time.Millisecond, []string{"emails"}, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+
+	require.NoError(t, q.SetProgress(ctx, popped, model.Progress{Percent: 50}))
+	require.NoError(t, q.Retry(ctx, popped, 0))
+
+	popped, err = q.Pop(ctx, 100*time.Millisecond, []string{"emails"}, testTypes)
+	require.NoError(t, err)
+	popped.Status = model.StatusCompleted
+	require.NoError(t, q.Ack(ctx, popped))
+
+	var got []model.TaskEvent
+	for len(got) < 4 {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of 4 events", len(got))
+		}
+	}
+
+	kinds := make([]model.EventType, len(got))
+	for i, e := range got {
+		kinds[i] = e.Event
+		assert.Equal(t, task.ID, e.TaskID)
+		assert.Equal(t, "echo", e.TaskType)
+		assert.Equal(t, "emails", e.Queue)
+	}
+	assert.Equal(t, []model.EventType{model.EventCreated, model.EventProgress, model.EventRetried, model.EventCompleted}, kinds)
+
+	require.NotNil(t, got[1].Progress)
+	assert.Equal(t, 50.0, got[1].Progress.Percent)
+	assert.Equal(t, 1, got[2].Retries)
+	assert.Equal(t, model.StatusCompleted, got[3].Status)
+}
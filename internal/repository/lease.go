@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// leaseTokenPrefix — токен текущей выдачи задачи. Pop и reaper, забравший
+// истекшую аренду, записывают новый токен, поэтому воркер, у которого задачу
+// уже забрали, не может ее подтвердить или изменить.
+const leaseTokenPrefix = "taskqueue:lease-token:"
+
+// withLease выполняет команды fill в транзакции, только если токен выдачи
+// задачи совпадает с t.DeliveryToken. Задача без токена (созданная до
+// появления токенов или забранная reaper без него) принимается только без
+// токена. Иначе возвращается model.ErrLeaseLost.
+func (q *RedisQueue) withLease(ctx context.Context, t *model.Task, fill func(pipe redis.Pipeliner)) error {
+	key := leaseTokenPrefix + t.ID
+	err := q.client.Watch(ctx, func(tx *redis.Tx) error {
+		token, err := tx.Get(ctx, key).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if token != t.DeliveryToken {
+			return model.ErrLeaseLost
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			fill(pipe)
+			return nil
+		})
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		// Токен сменился между проверкой и записью: задачу выдали заново
+		return model.ErrLeaseLost
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_StaleDeliveryRejected(t *testing.T) {
+	q, mr := setupTestQueue(t, WithVisibilityTimeout(50*time.Millisecond))
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	first, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.DeliveryToken)
+
+	// Первый воркер завис, reaper вернул задачу, и ее взял второй
+	time.Sleep(60 * time.Millisecond)
+	n, err := q.ReapExpired(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	second, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.Equal(t, tsk.ID, second.ID)
+	assert.NotEqual(t, first.DeliveryToken, second.DeliveryToken)
+
+	first.Status = model.StatusCompleted
+	assert.ErrorIs(t, q.Update(ctx, first), model.ErrLeaseLost)
+	assert.ErrorIs(t, q.Ack(ctx, first), model.ErrLeaseLost)
+	assert.ErrorIs(t, q.Retry(ctx, first, 0), model.ErrLeaseLost)
+	assert.ErrorIs(t, q.DeadLetter(ctx, first), model.ErrLeaseLost)
+
+	// Аренда и запись в processing второго воркера не тронуты
+	processing, _ := mr.List(processingKey)
+	assert.Equal(t, []string{tsk.ID}, processing)
+	_, err = mr.ZScore(leaseKey, tsk.ID)
+	assert.NoError(t, err)
+	stored, _ := q.Get(ctx, tsk.ID)
+	assert.Equal(t, model.StatusPending, stored.Status)
+
+	require.NoError(t, q.Ack(ctx, second))
+	assert.False(t, mr.Exists(processingKey))
+	assert.False(t, mr.Exists(leaseTokenPrefix+tsk.ID))
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const lockPrefix = "taskqueue:lock:"
+
+// TryLock захватывает распределенную блокировку на ttl. Успешно выполненное
+// разовое действие вроде срабатывания расписания блокировку не снимает, чтобы
+// его не повторила другая реплика.
+func (q *RedisQueue) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := q.client.SetNX(ctx, lockPrefix+key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("acquire lock: %w", err)
+	}
+	return ok, nil
+}
+
+// Unlock снимает блокировку, например если действие под ней не удалось и его
+// нужно повторить.
+func (q *RedisQueue) Unlock(ctx context.Context, key string) error {
+	if err := q.client.Del(ctx, lockPrefix+key).Err(); err != nil {
+		return fmt.Errorf("release lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	pausedQueuesKey = "taskqueue:paused:queues"
+	pausedTypesKey  = "taskqueue:paused:types"
+)
+
+// PauseQueue останавливает выдачу задач очереди всем пулам. Задачи продолжают
+// поступать в очередь, а уже выполняющиеся — завершаются.
+func (q *RedisQueue) PauseQueue(ctx context.Context, name string) error {
+	if err := q.client.SAdd(ctx, pausedQueuesKey, name).Err(); err != nil {
+		return fmt.Errorf("pause queue: %w", err)
+	}
+	return nil
+}
+
+func (q *RedisQueue) ResumeQueue(ctx context.Context, name string) error {
+	pipe := q.client.TxPipeline()
+	pipe.SRem(ctx, pausedQueuesKey, name)
+	pipe.Publish(ctx, wakeupChannel, name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("resume queue: %w", err)
+	}
+	return nil
+}
+
+// PauseType останавливает выдачу задач типа во всех очередях.
+func (q *RedisQueue) PauseType(ctx context.Context, taskType string) error {
+	if err := q.client.SAdd(ctx, pausedTypesKey, taskType).Err(); err != nil {
+		return fmt.Errorf("pause task type: %w", err)
+	}
+	return nil
+}
+
+func (q *RedisQueue) ResumeType(ctx context.Context, taskType string) error {
+	pipe := q.client.TxPipeline()
+	pipe.SRem(ctx, pausedTypesKey, taskType)
+	pipe.Publish(ctx, wakeupChannel, taskType)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("resume task type: %w", err)
+	}
+	return nil
+}
+
+func (q *RedisQueue) PausedQueues(ctx context.Context) (map[string]bool, error) {
+	return q.pausedSet(ctx, pausedQueuesKey)
+}
+
+func (q *RedisQueue) PausedTypes(ctx context.Context) (map[string]bool, error) {
+	return q.pausedSet(ctx, pausedTypesKey)
+}
+
+func (q *RedisQueue) pausedSet(ctx context.Context, key string) (map[string]bool, error) {
+	names, err := q.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list paused: %w", err)
+	}
+
+	paused := make(map[string]bool, len(names))
+	for _, name := range names {
+		paused[name] = true
+	}
+	return paused, nil
+}
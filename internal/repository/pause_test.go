@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_PauseQueue(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	email, _ := q.Push(ctx, "echo", json.RawMessage(`"1"`), model.EnqueueOptions{Queue: "emails"})
+	def, _ := q.Push(ctx, "echo", json.RawMessage(`"2"`), model.EnqueueOptions{})
+	queues := []string{"emails", model.DefaultQueue}
+
+	require.NoError(t, q.PauseQueue(ctx, "emails"))
+
+	popped, err := q.Pop(ctx, 100*time.Millisecond, queues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, def.ID, popped.ID)
+
+	popped, err = q.Pop(ctx, 100*time.Millisecond, queues, testTypes)
+	require.NoError(t, err)
+	assert.Nil(t, popped)
+
+	stats, err := q.QueueStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.False(t, stats[0].Paused)
+	assert.Equal(t, "emails", stats[1].Name)
+	assert.True(t, stats[1].Paused)
+	assert.Equal(t, int64(1), stats[1].Pending)
+
+	require.NoError(t, q.ResumeQueue(ctx, "emails"))
+
+	popped, err = q.Pop(ctx, 100*time.Millisecond, queues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, email.ID, popped.ID)
+}
+
+func TestQueue_PauseType(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	reindex, _ := q.Push(ctx, "reindex", json.RawMessage(`{}`), model.EnqueueOptions{Priority: model.PriorityHigh})
+	echo, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Priority: model.PriorityLow})
+
+	require.NoError(t, q.PauseType(ctx, "reindex"))
+	paused, err := q.PausedTypes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"reindex": true}, paused)
+
+	// Приостановленный тип пропускается даже при более высоком приоритете
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, echo.ID, popped.ID)
+
+	require.NoError(t, q.ResumeType(ctx, "reindex"))
+
+	popped, err = q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, reindex.ID, popped.ID)
+}
@@ -27,7 +27,7 @@ func (r *PostgresRepository) SaveHistory(ctx context.Context, t *model.Task) err
 			error = EXCLUDED.error, 
 			updated_at = EXCLUDED.updated_at;`
 
-	_, err := r.db.ExecContext(ctx, query, t.ID, t.Type, t.Status, t.Result, t.Error, t.CreatedAt, t.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, query, t.ID, t.Type, t.Status, string(t.Result), t.Error, t.CreatedAt, t.UpdatedAt)
 	return err
 }
 
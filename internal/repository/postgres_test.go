@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
@@ -57,7 +58,7 @@ func TestIntegration_PostgresRepository_SaveHistory(t *testing.T) {
 	assert.Equal(t, task.Type, dbType)
 
 	task.Status = model.StatusCompleted
-	task.Result = "100.0"
+	task.Result = json.RawMessage(`100.0`)
 	task.UpdatedAt = time.Now().Truncate(time.Millisecond)
 
 	err = repo.SaveHistory(ctx, task)
@@ -100,7 +101,7 @@ func TestIntegration_PostgresRepository_GetAnalytics(t *testing.T) {
 		ID:        "analytics-uuid-completed",
 		Type:      "render",
 		Status:    model.StatusCompleted,
-		Result:    "done",
+		Result:    json.RawMessage(`"done"`),
 		CreatedAt: baseTime.Add(-10 * time.Minute),
 		UpdatedAt: baseTime.Add(-8 * time.Minute), // duration = 120s
 	}
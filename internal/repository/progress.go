@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	progressPrefix        = "taskqueue:progress:"
+	progressChannelPrefix = "taskqueue:progress-updates:"
+)
+
+// SetProgress сохраняет прогресс задачи отдельным ключом, чтобы не
+// перезаписывать задачу, и публикует его подписчикам прогресса и потока событий.
+func (q *RedisQueue) SetProgress(ctx context.Context, t *model.Task, p model.Progress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal progress: %w", err)
+	}
+
+	event := model.NewTaskEvent(model.EventProgress, t)
+	event.Progress = &p
+
+	pipe := q.client.TxPipeline()
+	pipe.Set(ctx, progressPrefix+t.ID, data, 24*time.Hour)
+	pipe.Publish(ctx, progressChannelPrefix+t.ID, data)
+	pipe.Publish(ctx, eventsChannel, event)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("set progress: %w", err)
+	}
+	return nil
+}
+
+// Progress возвращает последний прогресс задачи или nil, если о нем не сообщали.
+func (q *RedisQueue) Progress(ctx context.Context, id string) (*model.Progress, error) {
+	data, err := q.client.Get(ctx, progressPrefix+id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get progress: %w", err)
+	}
+
+	var p model.Progress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal progress: %w", err)
+	}
+	return &p, nil
+}
+
+// SubscribeProgress возвращает обновления прогресса задачи, пока ctx не отменен.
+func (q *RedisQueue) SubscribeProgress(ctx context.Context, id string) <-chan model.Progress {
+	msgs := q.listen(ctx, progressChannelPrefix+id)
+	updates := make(chan model.Progress)
+
+	go func() {
+		defer close(updates)
+
+		for payload := range msgs {
+			var p model.Progress
+			if err := json.Unmarshal([]byte(payload), &p); err != nil {
+				continue
+			}
+			select {
+			case updates <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Progress(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	task, _ := q.Push(ctx, "slow", json.RawMessage(`null`), model.EnqueueOptions{})
+
+	p, err := q.Progress(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	updates := q.SubscribeProgress(ctx, task.ID)
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, q.SetProgress(ctx, task, model.Progress{Percent: 40, Message: "step 2 of 5"}))
+
+	p, err = q.Progress(ctx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, 40.0, p.Percent)
+	assert.Equal(t, "step 2 of 5", p.Message)
+
+	select {
+	case u := <-updates:
+		assert.Equal(t, 40.0, u.Percent)
+	case <-time.After(time.Second):
+		t.Fatal("progress update was not published")
+	}
+
+	// Прогресс не попадает в сохраненную задачу
+	stored, _ := q.Get(ctx, task.ID)
+	assert.Nil(t, stored.Progress)
+
+	require.NoError(t, q.Delete(ctx, task.ID))
+	assert.False(t, mr.Exists(progressPrefix+task.ID))
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer — сколько сообщений копится у подписчика. Если он не
+// успевает их забирать, новые сообщения для него отбрасываются, чтобы
+// медленный клиент не задерживал остальных.
+const subscriberBuffer = 100
+
+// hub разносит события задач, прогресс и завершение задач по подписчикам
+// процесса через одно соединение с Redis вместо соединения на каждого клиента.
+// Каналы задач слушаются по шаблону. Подписка оформляется при первом
+// подписчике и живет до Close.
+type hub struct {
+	once    sync.Once
+	mu      sync.Mutex
+	subs    map[string]map[chan string]struct{}
+	stop    context.CancelFunc
+	stopped bool
+}
+
+// listen возвращает сообщения канала channel, пока ctx не отменен. Канал
+// закрывается после отмены ctx или Close. Подписка активна уже к возврату,
+// поэтому состояние, прочитанное после вызова, не разойдется с сообщениями.
+func (q *RedisQueue) listen(ctx context.Context, channel string) <-chan string {
+	q.hub.once.Do(q.subscribeHub)
+
+	ch := make(chan string, subscriberBuffer)
+
+	q.hub.mu.Lock()
+	defer q.hub.mu.Unlock()
+	if q.hub.stopped {
+		close(ch)
+		return ch
+	}
+	if q.hub.subs[channel] == nil {
+		q.hub.subs[channel] = make(map[chan string]struct{})
+	}
+	q.hub.subs[channel][ch] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		q.hub.mu.Lock()
+		defer q.hub.mu.Unlock()
+		if _, ok := q.hub.subs[channel][ch]; !ok {
+			return
+		}
+		delete(q.hub.subs[channel], ch)
+		if len(q.hub.subs[channel]) == 0 {
+			delete(q.hub.subs, channel)
+		}
+		close(ch)
+	})
+	return ch
+}
+
+func (q *RedisQueue) subscribeHub() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := q.client.PSubscribe(ctx, eventsChannel, finishedChannelPrefix+"*", progressChannelPrefix+"*")
+
+	// Все шаблоны подписываются одной командой, поэтому достаточно первого
+	// подтверждения. Если Redis не ответил, подписка оформится позже сама.
+	confirmCtx, confirmCancel := context.WithTimeout(ctx, popPollInterval)
+	_, _ = sub.Receive(confirmCtx)
+	confirmCancel()
+
+	q.hub.mu.Lock()
+	q.hub.subs = make(map[string]map[chan string]struct{})
+	q.hub.stop = cancel
+	q.hub.mu.Unlock()
+
+	go func() {
+		defer sub.Close()
+
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					q.stopHub()
+					return
+				}
+				q.hub.route(msg.Channel, msg.Payload)
+			}
+		}
+	}()
+}
+
+func (h *hub) route(channel, payload string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[channel] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+}
+
+// stopHub закрывает подписку и каналы всех подписчиков.
+func (q *RedisQueue) stopHub() {
+	q.hub.mu.Lock()
+	defer q.hub.mu.Unlock()
+	if q.hub.stop != nil {
+		q.hub.stop()
+	}
+	for _, subs := range q.hub.subs {
+		for ch := range subs {
+			close(ch)
+		}
+	}
+	q.hub.subs = nil
+	q.hub.stopped = true
+}
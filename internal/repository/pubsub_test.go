@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_SubscribersShareConnection(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := q.SubscribeEvents(ctx)
+	first := q.SubscribeProgress(ctx, "1")
+	second := q.SubscribeProgress(ctx, "1")
+	other := q.SubscribeProgress(ctx, "2")
+
+	// Все клиенты слушают через одну подписку по шаблонам
+	assert.Equal(t, 3, mr.PubSubNumPat())
+	assert.Empty(t, mr.PubSubChannels(""))
+
+	require.NoError(t, q.SetProgress(ctx, &model.Task{ID: "1", Type: "echo"}, model.Progress{Percent: 30}))
+	for _, updates := range []<-chan model.Progress{first, second} {
+		select {
+		case p := <-updates:
+			assert.Equal(t, 30.0, p.Percent)
+		case <-time.After(time.Second):
+			t.Fatal("progress was not delivered")
+		}
+	}
+	select {
+	case e := <-events:
+		assert.Equal(t, model.EventProgress, e.Event)
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+	select {
+	case <-other:
+		t.Fatal("progress of another task was delivered")
+	default:
+	}
+
+	// После отмены каналы подписчиков закрываются
+	cancel()
+	for _, updates := range []<-chan model.Progress{first, second, other} {
+		select {
+		case _, ok := <-updates:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("subscription was not closed")
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/podushkina/taskqueue/internal/metrics"
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const leaseExpiredReason = "lease_expired"
+
+type HistorySaver interface {
+	SaveHistory(ctx context.Context, t *model.Task) error
+}
+
+// claimLeaseScript забирает истекшую аренду и выдает задачу reaper с новым
+// токеном: прежний воркер больше не сможет ее подтвердить. ZREM служит
+// захватом при нескольких репликах.
+var claimLeaseScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 1 then
+	redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// ReapExpired находит задачи с истекшей арендой, чей воркер пропал, и либо
+// возвращает их в очередь с увеличением Retries, либо переводит в failed.
+// Возвращает количество обработанных задач.
+func (q *RedisQueue) ReapExpired(ctx context.Context, m *metrics.Metrics, history HistorySaver) (int, error) {
+	now := time.Now()
+	ids, err := q.client.ZRangeByScore(ctx, leaseKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprint(now.UnixMilli()),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("find expired leases: %w", err)
+	}
+
+	reaped := 0
+	for _, id := range ids {
+		token := uuid.New().String()
+		claimed, err := claimLeaseScript.Run(ctx, q.client,
+			[]string{leaseKey, leaseTokenPrefix + id},
+			id, token, (24 * time.Hour).Milliseconds(),
+		).Int()
+		if err != nil {
+			return reaped, fmt.Errorf("claim expired lease: %w", err)
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		if err := q.reap(ctx, id, token, m, history); err != nil {
+			return reaped, err
+		}
+		reaped++
+	}
+
+	return reaped, nil
+}
+
+func (q *RedisQueue) reap(ctx context.Context, id, token string, m *metrics.Metrics, history HistorySaver) error {
+	t, err := q.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return q.release(ctx, id)
+	}
+	t.DeliveryToken = token
+	if t.Status.Finished() {
+		// Воркер сохранил итог, но не успел подтвердить задачу (сбой Ack) или
+		// аренда истекла уже после завершения: повторять нечего
+		if err := q.release(ctx, id); err != nil {
+			return err
+		}
+		return q.releaseUnique(ctx, t)
+	}
+
+	if t.Retries < t.MaxRetry {
+		m.IncTaskRetries(t.Type, leaseExpiredReason)
+		return q.Retry(ctx, t, 0)
+	}
+
+	m.IncDeadLetter(t.Type)
+	m.IncTasksProcessed(t.Type, "failed")
+
+	t.Error = "lease expired: worker did not acknowledge the task"
+	if err := q.DeadLetter(ctx, t); err != nil {
+		return err
+	}
+	if history != nil {
+		if err := history.SaveHistory(ctx, t); err != nil {
+			slog.Error("Failed to save history", "task_id", t.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (q *RedisQueue) StartReaper(ctx context.Context, m *metrics.Metrics, history HistorySaver, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := q.ReapExpired(ctx, m, history)
+				if err != nil && ctx.Err() == nil {
+					slog.Error("Reaper error", "error", err)
+				}
+				if n > 0 {
+					slog.Warn("Recovered tasks with expired lease", "count", n)
+				}
+			}
+		}
+	}()
+}
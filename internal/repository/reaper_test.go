@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHistory struct {
+	saved []*model.Task
+}
+
+func (m *mockHistory) SaveHistory(ctx context.Context, t *model.Task) error {
+	m.saved = append(m.saved, t)
+	return nil
+}
+
+func TestReaper_RequeuesExpiredTask(t *testing.T) {
+	q, mr := setupTestQueue(t, WithVisibilityTimeout(50*time.Millisecond))
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	popped.Status = model.StatusProcessing
+	require.NoError(t, q.Update(ctx, popped))
+
+	n, err := q.ReapExpired(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	time.Sleep(60 * time.Millisecond)
+
+	n, err = q.ReapExpired(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := q.Get(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPending, stored.Status)
+	assert.Equal(t, 1, stored.Retries)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{tsk.ID}, pending)
+	assert.False(t, mr.Exists(processingKey))
+}
+
+func TestReaper_FailsTaskAtMaxRetry(t *testing.T) {
+	q, mr := setupTestQueue(t, WithVisibilityTimeout(50*time.Millisecond))
+	defer mr.Close()
+	ctx := context.Background()
+	mh := &mockHistory{}
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	popped.Retries = popped.MaxRetry
+	require.NoError(t, q.Update(ctx, popped))
+
+	time.Sleep(60 * time.Millisecond)
+
+	n, err := q.ReapExpired(ctx, nil, mh)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := q.Get(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusFailed, stored.Status)
+	assert.Contains(t, stored.Error, "lease expired")
+	require.Len(t, mh.saved, 1)
+	assert.Equal(t, tsk.ID, mh.saved[0].ID)
+
+	assert.False(t, mr.Exists(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo")))
+	assert.False(t, mr.Exists(processingKey))
+	dlq, _ := mr.List(dlqKey)
+	assert.Equal(t, []string{tsk.ID}, dlq)
+}
+
+func TestReaper_ReleasesFinishedTask(t *testing.T) {
+	q, mr := setupTestQueue(t, WithVisibilityTimeout(50*time.Millisecond))
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	// Итог сохранен, но Ack не дошел: аренда осталась
+	popped.Status = model.StatusCompleted
+	require.NoError(t, q.Update(ctx, popped))
+
+	time.Sleep(60 * time.Millisecond)
+
+	n, err := q.ReapExpired(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := q.Get(ctx, tsk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusCompleted, stored.Status)
+	assert.Equal(t, 0, stored.Retries)
+	assert.False(t, mr.Exists(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo")))
+	assert.False(t, mr.Exists(processingKey))
+	assert.False(t, mr.Exists(leaseKey))
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,15 +17,142 @@ import (
 )
 
 const (
-	queueKey   = "taskqueue:pending"
-	taskPrefix = "taskqueue:task:"
+	pendingPrefix = "taskqueue:queue:"
+	queuesKey     = "taskqueue:queues"
+	processingKey = "taskqueue:processing"
+	leaseKey      = "taskqueue:leases"
+	retryKey      = "taskqueue:retry"
+	scheduledKey  = "taskqueue:scheduled"
+	taskPrefix    = "taskqueue:task:"
+
+	idempotencyPrefix = "taskqueue:idempotency:"
+	throughputPrefix  = "taskqueue:throughput:"
+
+	// legacyPendingKey — общий список pending прежней версии, из которого
+	// задачи переносятся при старте.
+	legacyPendingKey = "taskqueue:pending"
+
+	// throughputWindow — окно, за которое GET /queues считает завершенные задачи.
+	throughputWindow = 5 * time.Minute
+
+	defaultVisibilityTimeout = time.Minute
+	defaultIdempotencyWindow = 24 * time.Hour
+	// popPollInterval — запасной интервал опроса на случай, если оповещение из
+	// wakeupChannel потеряно, например при переподключении к Redis.
+	popPollInterval = time.Second
 )
 
+// pushAttempts ограничивает повторы Push, если задача, найденная по ключу
+// идемпотентности или уникальности, исчезла до ее чтения.
+const pushAttempts = 3
+
+// pushScript сохраняет задачу и кладет ее в список pending (или в sorted set,
+// если передан score). При заданном ключе идемпотентности повторный вызов в
+// пределах окна возвращает ID исходной задачи, не создавая новую. Для
+// уникальной задачи ключ уникальности захватывается, только если его держатель
+// уже не существует. Возвращает пару {id, результат}.
+var pushScript = redis.NewScript(`
+if KEYS[3] ~= '' then
+	local existing = redis.call('GET', KEYS[3])
+	if existing and redis.call('EXISTS', ARGV[6] .. existing) == 1 then
+		return {existing, 'duplicate'}
+	end
+end
+if KEYS[4] ~= '' then
+	local holder = redis.call('GET', KEYS[4])
+	if holder and redis.call('EXISTS', ARGV[6] .. holder) == 1 then
+		return {holder, 'conflict'}
+	end
+	redis.call('SET', KEYS[4], ARGV[1], 'PX', ARGV[7])
+end
+if KEYS[3] ~= '' then
+	redis.call('SET', KEYS[3], ARGV[1], 'PX', ARGV[5])
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+redis.call('SADD', KEYS[5], ARGV[8])
+if ARGV[4] ~= '' then
+	redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+else
+	redis.call('RPUSH', KEYS[2], ARGV[1])
+	redis.call('PUBLISH', ARGV[9], ARGV[8])
+end
+return {ARGV[1], 'created'}
+`)
+
+// moveHeadScript переносит первый ID списка в другой список, только если он
+// совпадает с ожидаемым: между чтением и переносом список мог измениться.
+var moveHeadScript = redis.NewScript(`
+if redis.call('LINDEX', KEYS[1], 0) == ARGV[1] then
+	redis.call('LPOP', KEYS[1])
+	redis.call('RPUSH', KEYS[2], ARGV[1])
+	redis.call('PUBLISH', ARGV[2], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// popScript забирает первую задачу из переданных списков pending, переносит ее
+// в processing и выдает аренду с токеном ARGV[2] одной атомарной операцией. Для
+// каждого списка в ARGV передаются его очередь и тип; списки приостановленных
+// очередей и типов пропускаются.
+var popScript = redis.NewScript(`
+for i = 5, #KEYS do
+	local n = (i - 5) * 2 + 5
+	if redis.call('SISMEMBER', KEYS[3], ARGV[n]) == 0 and redis.call('SISMEMBER', KEYS[4], ARGV[n + 1]) == 0 then
+		local id = redis.call('LMOVE', KEYS[i], KEYS[1], 'LEFT', 'RIGHT')
+		if id then
+			redis.call('ZADD', KEYS[2], ARGV[1], id)
+			redis.call('SET', ARGV[3] .. id, ARGV[2], 'PX', ARGV[4])
+			return id
+		end
+	end
+end
+return false
+`)
+
 type RedisQueue struct {
-	client *redis.Client
+	client            *redis.Client
+	visibility        time.Duration
+	weights           map[model.Priority]int
+	idempotencyWindow time.Duration
+	wake              waker
+	hub               hub
+}
+
+type Option func(*RedisQueue)
+
+// WithVisibilityTimeout задает время аренды задачи воркером. Если задача не
+// подтверждена (Ack/Nack) за это время, она возвращается в очередь.
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(q *RedisQueue) {
+		if d > 0 {
+			q.visibility = d
+		}
+	}
+}
+
+// WithPriorityWeights включает взвешенный опрос приоритетов: на каждом Pop порядок
+// списков выбирается случайно пропорционально весам. По умолчанию приоритеты
+// опрашиваются строго от high к low.
+func WithPriorityWeights(weights map[model.Priority]int) Option {
+	return func(q *RedisQueue) {
+		if len(weights) > 0 {
+			q.weights = weights
+		}
+	}
+}
+
+// WithIdempotencyWindow задает, как долго ключ идемпотентности защищает от
+// повторного создания задачи.
+func WithIdempotencyWindow(d time.Duration) Option {
+	return func(q *RedisQueue) {
+		if d > 0 {
+			q.idempotencyWindow = d
+		}
+	}
 }
 
-func NewRedisQueue(addr, password string, db int) (*RedisQueue, error) {
+func NewRedisQueue(addr, password string, db int, opts ...Option) (*RedisQueue, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
@@ -35,22 +166,104 @@ func NewRedisQueue(addr, password string, db int) (*RedisQueue, error) {
 		return nil, fmt.Errorf("redis connection failed: %w", err)
 	}
 
-	return &RedisQueue{client: client}, nil
+	q := &RedisQueue{
+		client:            client,
+		visibility:        defaultVisibilityTimeout,
+		idempotencyWindow: defaultIdempotencyWindow,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q, nil
 }
 
 func (q *RedisQueue) Close() error {
+	q.stopWakeups()
+	q.stopHub()
 	return q.client.Close()
 }
 
-func (q *RedisQueue) Push(ctx context.Context, taskType, payload string) (*model.Task, error) {
+// pendingKey возвращает список pending для очереди, приоритета и типа задачи.
+// Отдельные списки по типам позволяют пулу забирать только те типы, для которых
+// у него есть обработчики.
+func pendingKey(queue string, p model.Priority, taskType string) string {
+	return pendingPrefix + queue + ":" + string(p.OrDefault()) + ":" + taskType
+}
+
+func taskPendingKey(t *model.Task) string {
+	return pendingKey(t.QueueName(), t.Priority, t.Type)
+}
+
+// MigrateLegacyPending переносит задачи из общего списка taskqueue:pending
+// прежней версии в списки именованных очередей.
+func (q *RedisQueue) MigrateLegacyPending(ctx context.Context) (int, error) {
+	moved := 0
+	for {
+		id, err := q.client.LIndex(ctx, legacyPendingKey, 0).Result()
+		if err == redis.Nil {
+			return moved, nil
+		}
+		if err != nil {
+			return moved, fmt.Errorf("read legacy queue: %w", err)
+		}
+
+		t, err := q.Get(ctx, id)
+		if err != nil {
+			return moved, err
+		}
+		if t == nil {
+			q.client.LRem(ctx, legacyPendingKey, 1, id)
+			continue
+		}
+
+		n, err := moveHeadScript.Run(ctx, q.client, []string{legacyPendingKey, taskPendingKey(t)}, id, wakeupChannel).Int()
+		if err != nil {
+			return moved, fmt.Errorf("migrate task: %w", err)
+		}
+		moved += n
+	}
+}
+
+func (q *RedisQueue) Push(ctx context.Context, taskType string, payload json.RawMessage, opts model.EnqueueOptions) (*model.Task, error) {
+	now := time.Now()
 	t := &model.Task{
 		ID:        uuid.New().String(),
 		Type:      taskType,
+		Queue:     opts.Queue,
 		Payload:   payload,
 		Status:    model.StatusPending,
+		Priority:  opts.Priority.OrDefault(),
 		MaxRetry:  model.DefaultMaxRetry,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
+
+		IdempotencyKey: opts.IdempotencyKey,
+		Policy:         opts.Policy,
+		Callback:       opts.Callback,
+	}
+	if t.Queue == "" {
+		t.Queue = model.DefaultQueue
+	}
+	if opts.Policy != nil && opts.Policy.MaxRetry != nil {
+		t.MaxRetry = *opts.Policy.MaxRetry
+	}
+
+	ttl := 24 * time.Hour
+	if opts.RunAt.After(now) {
+		runAt := opts.RunAt
+		t.Status = model.StatusScheduled
+		t.RunAt = &runAt
+		// Ключ должен дожить до запуска задачи и еще сутки после него
+		ttl += runAt.Sub(now)
+	}
+
+	uniqueTTL := ttl
+	if opts.Unique {
+		t.UniqueKey = uniqueKey(taskType, payload, opts.UniqueKey)
+		if opts.UniqueTTL > 0 {
+			uniqueTTL = opts.UniqueTTL
+		}
 	}
 
 	data, err := json.Marshal(t)
@@ -58,49 +271,266 @@ func (q *RedisQueue) Push(ctx context.Context, taskType, payload string) (*model
 		return nil, fmt.Errorf("marshal task: %w", err)
 	}
 
-	pipe := q.client.Pipeline()
-	pipe.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour)
-	pipe.RPush(ctx, queueKey, t.ID)
+	target, score := taskPendingKey(t), ""
+	if t.Status == model.StatusScheduled {
+		target, score = scheduledKey, fmt.Sprint(t.RunAt.UnixMilli())
+	}
 
-	if _, err := pipe.Exec(ctx); err != nil {
-		return nil, fmt.Errorf("push task: %w", err)
+	idemKey := ""
+	if opts.IdempotencyKey != "" {
+		idemKey = idempotencyPrefix + opts.IdempotencyKey
 	}
 
-	return t, nil
+	uniqKey := ""
+	if t.UniqueKey != "" {
+		uniqKey = uniquePrefix + t.UniqueKey
+	}
+
+	// Задача-владелец ключа может быть удалена или истечь между скриптом и
+	// чтением; тогда ключ уже свободен и скрипт создаст задачу при повторе
+	for range pushAttempts {
+		res, err := pushScript.Run(ctx, q.client,
+			[]string{taskPrefix + t.ID, target, idemKey, uniqKey, queuesKey},
+			t.ID, data, ttl.Milliseconds(), score, q.idempotencyWindow.Milliseconds(), taskPrefix, uniqueTTL.Milliseconds(), t.Queue, wakeupChannel,
+		).StringSlice()
+		if err != nil {
+			return nil, fmt.Errorf("push task: %w", err)
+		}
+
+		id, outcome := res[0], res[1]
+		if outcome == "created" {
+			q.notify(ctx, model.EventCreated, t)
+			return t, nil
+		}
+
+		existing, err := q.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			continue
+		}
+		if outcome == "conflict" {
+			return existing, model.ErrDuplicateTask
+		}
+		return existing, model.ErrDuplicateRequest
+	}
+	return nil, fmt.Errorf("push task: existing task kept disappearing after %d attempts", pushAttempts)
 }
 
-func (q *RedisQueue) Retry(ctx context.Context, t *model.Task) error {
+// Retry снимает аренду и откладывает задачу в taskqueue:retry до наступления
+// времени повтора. При delay <= 0 задача сразу возвращается в pending.
+func (q *RedisQueue) Retry(ctx context.Context, t *model.Task, delay time.Duration) error {
 	t.Retries++
 	t.Status = model.StatusPending
 	t.UpdatedAt = time.Now()
+	t.NextRetryAt = nil
+	// Как и у отложенной задачи, ключ должен дожить до повтора и еще сутки после
+	ttl := 24 * time.Hour
+	if delay > 0 {
+		due := t.UpdatedAt.Add(delay)
+		t.NextRetryAt = &due
+		ttl += delay
+	}
 
 	data, err := json.Marshal(t)
 	if err != nil {
 		return fmt.Errorf("marshal task: %w", err)
 	}
 
-	pipe := q.client.Pipeline()
-	pipe.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour)
-	pipe.RPush(ctx, queueKey, t.ID)
-
-	if _, err := pipe.Exec(ctx); err != nil {
+	err = q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+		pipe.Set(ctx, taskPrefix+t.ID, data, ttl)
+		pipe.LRem(ctx, processingKey, 1, t.ID)
+		pipe.ZRem(ctx, leaseKey, t.ID)
+		pipe.Del(ctx, leaseTokenPrefix+t.ID)
+		if t.NextRetryAt != nil {
+			pipe.ZAdd(ctx, retryKey, redis.Z{Score: float64(t.NextRetryAt.UnixMilli()), Member: t.ID})
+		} else {
+			pipe.RPush(ctx, taskPendingKey(t), t.ID)
+			pipe.Publish(ctx, wakeupChannel, t.QueueName())
+		}
+		publishEvent(ctx, pipe, model.EventRetried, t)
+	})
+	if err != nil {
 		return fmt.Errorf("retry task: %w", err)
 	}
 
 	return nil
 }
 
-func (q *RedisQueue) Pop(ctx context.Context, timeout time.Duration) (*model.Task, error) {
-	result, err := q.client.BLPop(ctx, timeout, queueKey).Result()
+// Pop атомарно переносит ID задачи одного из типов types из pending в processing
+// и выдает на нее аренду. Очереди queues опрашиваются в переданном порядке.
+// Задача остается в processing до вызова Ack или Nack. Пока не истечет timeout,
+// Pop ждет оповещения о новых задачах и опрашивает списки повторно по нему или
+// раз в popPollInterval.
+func (q *RedisQueue) Pop(ctx context.Context, timeout time.Duration, queues, types []string) (*model.Task, error) {
+	deadline := time.Now().Add(timeout)
+
+	var wake <-chan struct{}
+	for {
+		taskID, token, err := q.popOnce(ctx, queues, types)
+		if err != nil {
+			return nil, err
+		}
+		if taskID != "" {
+			t, err := q.Get(ctx, taskID)
+			if err != nil {
+				return nil, err
+			}
+			if t == nil {
+				// Задача удалена или истек TTL ключа, пока ID лежал в очереди
+				return nil, q.release(ctx, taskID)
+			}
+			t.DeliveryToken = token
+			return t, nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, nil
+		}
+		if wake == nil {
+			// Подписываемся и сразу опрашиваем снова: задача могла появиться
+			// до того, как мы получили канал
+			wake = q.wakeups()
+			continue
+		}
+
+		timer := time.NewTimer(min(wait, popPollInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+		wake = q.wakeups()
+	}
+}
+
+// popOnce опрашивает очереди по порядку, внутри очереди — приоритеты, а внутри
+// приоритета — типы в случайном порядке, чтобы ни один тип не голодал.
+func (q *RedisQueue) popOnce(ctx context.Context, queues, types []string) (string, string, error) {
+	if len(queues) == 0 || len(types) == 0 {
+		return "", "", nil
+	}
+
+	leaseDeadline := time.Now().Add(q.visibility).UnixMilli()
+	token := uuid.New().String()
+	keys := []string{processingKey, leaseKey, pausedQueuesKey, pausedTypesKey}
+	args := []any{leaseDeadline, token, leaseTokenPrefix, (24 * time.Hour).Milliseconds()}
+	for _, queue := range queues {
+		for _, p := range q.priorityOrder() {
+			for _, i := range rand.Perm(len(types)) {
+				keys = append(keys, pendingKey(queue, p, types[i]))
+				args = append(args, queue, types[i])
+			}
+		}
+	}
+
+	taskID, err := popScript.Run(ctx, q.client, keys, args...).Text()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, nil
+			return "", "", nil
 		}
-		return nil, fmt.Errorf("pop task: %w", err)
+		return "", "", fmt.Errorf("pop task: %w", err)
+	}
+	return taskID, token, nil
+}
+
+// priorityOrder возвращает порядок опроса приоритетов: строгий по умолчанию или
+// взвешенную случайную выборку без возвращения, если заданы веса.
+func (q *RedisQueue) priorityOrder() []model.Priority {
+	if q.weights == nil {
+		return model.Priorities
+	}
+
+	return model.WeightedOrder(model.Priorities, func(p model.Priority) int {
+		return q.weights[p]
+	})
+}
+
+// ExtendLease продлевает аренду задачи так, чтобы она не истекла раньше, чем
+// через d плюс visibility timeout. Укоротить аренду нельзя.
+func (q *RedisQueue) ExtendLease(ctx context.Context, t *model.Task, d time.Duration) error {
+	deadline := time.Now().Add(d + q.visibility).UnixMilli()
+	err := q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+		pipe.ZAddArgs(ctx, leaseKey, redis.ZAddArgs{
+			XX:      true,
+			GT:      true,
+			Members: []redis.Z{{Score: float64(deadline), Member: t.ID}},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("extend lease: %w", err)
 	}
+	return nil
+}
+
+// Ack подтверждает завершение обработки и снимает аренду.
+func (q *RedisQueue) Ack(ctx context.Context, t *model.Task) error {
+	err := q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+		pipe.LRem(ctx, processingKey, 1, t.ID)
+		pipe.ZRem(ctx, leaseKey, t.ID)
+		pipe.Del(ctx, leaseTokenPrefix+t.ID)
+		countProcessed(ctx, pipe, t.QueueName(), model.StatusCompleted)
+		publishFinished(ctx, pipe, t.ID)
+		publishEvent(ctx, pipe, model.EventCompleted, t)
+		enqueueCallback(ctx, pipe, model.EventCompleted, t)
+	})
+	if err != nil {
+		return fmt.Errorf("ack task: %w", err)
+	}
+	return q.releaseUnique(ctx, t)
+}
 
-	taskID := result[1]
-	return q.Get(ctx, taskID)
+// throughputKey возвращает минутный счетчик завершенных задач очереди.
+func throughputKey(queue string, at time.Time) string {
+	return throughputPrefix + queue + ":" + strconv.FormatInt(at.Unix()/60, 10)
+}
+
+// countProcessed увеличивает счетчик задач очереди со статусом status за
+// текущую минуту. Счетчики хранятся чуть дольше окна throughputWindow.
+func countProcessed(ctx context.Context, pipe redis.Pipeliner, queue string, status model.Status) {
+	key := throughputKey(queue, time.Now())
+	pipe.HIncrBy(ctx, key, string(status), 1)
+	pipe.Expire(ctx, key, throughputWindow+time.Minute)
+}
+
+// Nack возвращает задачу в начало очереди без увеличения счетчика попыток.
+func (q *RedisQueue) Nack(ctx context.Context, t *model.Task) error {
+	t.Status = model.StatusPending
+	t.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal task: %w", err)
+	}
+
+	err = q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+		pipe.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour)
+		pipe.LRem(ctx, processingKey, 1, t.ID)
+		pipe.ZRem(ctx, leaseKey, t.ID)
+		pipe.Del(ctx, leaseTokenPrefix+t.ID)
+		pipe.LPush(ctx, taskPendingKey(t), t.ID)
+		pipe.Publish(ctx, wakeupChannel, t.QueueName())
+	})
+	if err != nil {
+		return fmt.Errorf("nack task: %w", err)
+	}
+
+	return nil
+}
+
+func (q *RedisQueue) release(ctx context.Context, id string) error {
+	pipe := q.client.TxPipeline()
+	pipe.LRem(ctx, processingKey, 1, id)
+	pipe.ZRem(ctx, leaseKey, id)
+	pipe.Del(ctx, leaseTokenPrefix+id)
+
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 func (q *RedisQueue) Get(ctx context.Context, id string) (*model.Task, error) {
@@ -128,7 +558,15 @@ func (q *RedisQueue) Update(ctx context.Context, t *model.Task) error {
 		return fmt.Errorf("marshal task: %w", err)
 	}
 
-	if err := q.client.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour).Err(); err != nil {
+	// Воркер обновляет задачу только пока владеет ее выдачей
+	if t.DeliveryToken != "" {
+		err = q.withLease(ctx, t, func(pipe redis.Pipeliner) {
+			pipe.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour)
+		})
+	} else {
+		err = q.client.Set(ctx, taskPrefix+t.ID, data, 24*time.Hour).Err()
+	}
+	if err != nil {
 		return fmt.Errorf("update task: %w", err)
 	}
 
@@ -173,12 +611,162 @@ func (q *RedisQueue) List(ctx context.Context) ([]*model.Task, error) {
 }
 
 func (q *RedisQueue) Delete(ctx context.Context, id string) error {
-	if err := q.client.Del(ctx, taskPrefix+id).Err(); err != nil {
+	t, err := q.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.Del(ctx, taskPrefix+id, progressPrefix+id, callbacksPrefix+id, leaseTokenPrefix+id)
+	pipe.LRem(ctx, dlqKey, 0, id)
+	pipe.ZRem(ctx, retryKey, id)
+	pipe.ZRem(ctx, scheduledKey, id)
+	pipe.ZRem(ctx, callbackDueKey, id)
+	pipe.HDel(ctx, callbackDeliveriesKey, id)
+	if t != nil {
+		pipe.LRem(ctx, taskPendingKey(t), 0, id)
+	}
+	publishFinished(ctx, pipe, id)
+
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("delete task: %w", err)
 	}
+
+	if t != nil {
+		return q.releaseUnique(ctx, t)
+	}
 	return nil
 }
 
+type pendingList struct {
+	queue    string
+	priority model.Priority
+	taskType string
+	length   int64
+}
+
+// pendingLists возвращает длины всех списков pending.
+func (q *RedisQueue) pendingLists(ctx context.Context) ([]pendingList, error) {
+	var keys []string
+	iter := q.client.Scan(ctx, 0, pendingPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("scan pending lists: %w", err)
+	}
+
+	lists := make([]pendingList, 0, len(keys))
+	cmds := make([]*redis.IntCmd, 0, len(keys))
+	pipe := q.client.Pipeline()
+	for _, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, pendingPrefix), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		lists = append(lists, pendingList{queue: parts[0], priority: model.Priority(parts[1]), taskType: parts[2]})
+		cmds = append(cmds, pipe.LLen(ctx, key))
+	}
+	if len(cmds) == 0 {
+		return lists, nil
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("count pending: %w", err)
+	}
+	for i, cmd := range cmds {
+		lists[i].length = cmd.Val()
+	}
+	return lists, nil
+}
+
+// PendingByType возвращает число задач в очереди по типам.
+func (q *RedisQueue) PendingByType(ctx context.Context) (map[string]int64, error) {
+	lists, err := q.pendingLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, l := range lists {
+		counts[l.taskType] += l.length
+	}
+	return counts, nil
+}
+
+// queueNames возвращает в алфавитном порядке все известные очереди: те, в
+// которые ставились задачи, приостановленные и те, у которых есть списки pending.
+func (q *RedisQueue) queueNames(ctx context.Context, lists []pendingList) ([]string, error) {
+	names, err := q.client.SUnion(ctx, queuesKey, pausedQueuesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list queues: %w", err)
+	}
+	for _, l := range lists {
+		names = append(names, l.queue)
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// QueueStats возвращает глубину и пропускную способность каждой очереди.
+func (q *RedisQueue) QueueStats(ctx context.Context) ([]model.QueueStats, error) {
+	lists, err := q.pendingLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := q.queueNames(ctx, lists)
+	if err != nil {
+		return nil, err
+	}
+
+	paused, err := q.PausedQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]int64)
+	for _, l := range lists {
+		pending[l.queue] += l.length
+	}
+
+	minutes := int(throughputWindow / time.Minute)
+	now := time.Now()
+	pipe := q.client.Pipeline()
+	cmds := make([][]*redis.MapStringStringCmd, len(names))
+	for i, name := range names {
+		for m := range minutes {
+			cmds[i] = append(cmds[i], pipe.HGetAll(ctx, throughputKey(name, now.Add(-time.Duration(m)*time.Minute))))
+		}
+	}
+	if len(names) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("read throughput: %w", err)
+		}
+	}
+
+	stats := make([]model.QueueStats, len(names))
+	for i, name := range names {
+		s := model.QueueStats{
+			Name:    name,
+			Pending: pending[name],
+			Paused:  paused[name],
+			Window:  model.Duration(throughputWindow),
+		}
+		for _, cmd := range cmds[i] {
+			counts := cmd.Val()
+			completed, _ := strconv.ParseInt(counts[string(model.StatusCompleted)], 10, 64)
+			failed, _ := strconv.ParseInt(counts[string(model.StatusFailed)], 10, 64)
+			s.Completed += completed
+			s.Failed += failed
+		}
+		s.Throughput = float64(s.Completed) / float64(minutes)
+		stats[i] = s
+	}
+	return stats, nil
+}
+
+// StartQueueDepthCollector периодически экспортирует глубину очередей и
+// признаки паузы очередей и типов задач.
 func (q *RedisQueue) StartQueueDepthCollector(ctx context.Context, m *metrics.Metrics, interval time.Duration) {
 	if m == nil {
 		return
@@ -187,16 +775,67 @@ func (q *RedisQueue) StartQueueDepthCollector(ctx context.Context, m *metrics.Me
 	ticker := time.NewTicker(interval)
 	go func() {
 		defer ticker.Stop()
+		// Типы, о паузе которых уже сообщали: после снятия паузы для них
+		// нужно выставить 0
+		reportedTypes := make(map[string]bool)
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				length, err := q.client.LLen(ctx, queueKey).Result()
-				if err == nil {
-					m.QueueDepth.WithLabelValues("default").Set(float64(length))
-				}
+				q.collectQueueDepth(ctx, m)
+				q.collectPausedTypes(ctx, m, reportedTypes)
 			}
 		}
 	}()
 }
+
+func (q *RedisQueue) collectQueueDepth(ctx context.Context, m *metrics.Metrics) {
+	lists, err := q.pendingLists(ctx)
+	if err != nil {
+		return
+	}
+	queues, err := q.queueNames(ctx, lists)
+	if err != nil {
+		return
+	}
+	paused, err := q.PausedQueues(ctx)
+	if err != nil {
+		return
+	}
+
+	depth := make(map[string]map[model.Priority]int64)
+	for _, l := range lists {
+		if depth[l.queue] == nil {
+			depth[l.queue] = make(map[model.Priority]int64)
+		}
+		depth[l.queue][l.priority] += l.length
+	}
+	for _, queue := range queues {
+		for _, p := range model.Priorities {
+			m.QueueDepth.WithLabelValues(queue, string(p)).Set(float64(depth[queue][p]))
+		}
+		m.Paused.WithLabelValues("queue", queue).Set(boolGauge(paused[queue]))
+	}
+}
+
+func (q *RedisQueue) collectPausedTypes(ctx context.Context, m *metrics.Metrics, reported map[string]bool) {
+	paused, err := q.PausedTypes(ctx)
+	if err != nil {
+		return
+	}
+
+	for name := range paused {
+		reported[name] = true
+	}
+	for name := range reported {
+		m.Paused.WithLabelValues("task_type", name).Set(boolGauge(paused[name]))
+	}
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
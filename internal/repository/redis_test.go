@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -11,12 +12,17 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func setupTestQueue(t *testing.T) (*RedisQueue, *miniredis.Miniredis) {
+// testTypes — типы задач, которые тесты забирают из очереди.
+var testTypes = []string{"echo", "fail_task", "reindex"}
+
+var testQueues = []string{model.DefaultQueue}
+
+func setupTestQueue(t *testing.T, opts ...Option) (*RedisQueue, *miniredis.Miniredis) {
 	mr, err := miniredis.Run()
 	if err != nil {
 		t.Fatalf("failed to start miniredis: %v", err)
 	}
-	q, err := NewRedisQueue(mr.Addr(), "", 0)
+	q, err := NewRedisQueue(mr.Addr(), "", 0, opts...)
 	if err != nil {
 		t.Fatalf("failed to create queue: %v", err)
 	}
@@ -28,17 +34,17 @@ func TestQueue_PushAndPop(t *testing.T) {
 	defer mr.Close()
 	ctx := context.Background()
 
-	createdTask, err := q.Push(ctx, "echo", "hello payload")
+	createdTask, err := q.Push(ctx, "echo", json.RawMessage(`{"message":"hello payload"}`), model.EnqueueOptions{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, createdTask.ID)
 	assert.Equal(t, model.StatusPending, createdTask.Status)
 
-	poppedTask, err := q.Pop(ctx, 1*time.Second)
+	poppedTask, err := q.Pop(ctx, 1*time.Second, testQueues, testTypes)
 	require.NoError(t, err)
 	require.NotNil(t, poppedTask)
 
 	assert.Equal(t, createdTask.ID, poppedTask.ID)
-	assert.Equal(t, "hello payload", poppedTask.Payload)
+	assert.JSONEq(t, `{"message":"hello payload"}`, string(poppedTask.Payload))
 }
 
 func TestQueue_Update(t *testing.T) {
@@ -46,9 +52,9 @@ func TestQueue_Update(t *testing.T) {
 	defer mr.Close()
 	ctx := context.Background()
 
-	tsk, _ := q.Push(ctx, "echo", "data")
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
 	tsk.Status = model.StatusCompleted
-	tsk.Result = "done"
+	tsk.Result = json.RawMessage(`{"rows":42}`)
 
 	err := q.Update(ctx, tsk)
 	assert.NoError(t, err)
@@ -56,7 +62,7 @@ func TestQueue_Update(t *testing.T) {
 	updated, err := q.Get(ctx, tsk.ID)
 	require.NoError(t, err)
 	assert.Equal(t, model.StatusCompleted, updated.Status)
-	assert.Equal(t, "done", updated.Result)
+	assert.JSONEq(t, `{"rows":42}`, string(updated.Result))
 }
 
 func TestQueue_Retry(t *testing.T) {
@@ -64,13 +70,13 @@ func TestQueue_Retry(t *testing.T) {
 	defer mr.Close()
 	ctx := context.Background()
 
-	tsk, err := q.Push(ctx, "fail_task", "data")
+	tsk, err := q.Push(ctx, "fail_task", json.RawMessage(`"data"`), model.EnqueueOptions{})
 	require.NoError(t, err)
 
 	tsk.Status = model.StatusProcessing
 	tsk.Retries = 0
 
-	err = q.Retry(ctx, tsk)
+	err = q.Retry(ctx, tsk, 0)
 	assert.NoError(t, err)
 
 	updatedTask, err := q.Get(ctx, tsk.ID)
@@ -84,7 +90,7 @@ func TestQueue_PopEmpty(t *testing.T) {
 	defer mr.Close()
 	ctx := context.Background()
 
-	tsk, err := q.Pop(ctx, 100*time.Millisecond)
+	tsk, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
 	assert.NoError(t, err)
 	assert.Nil(t, tsk)
 }
@@ -94,8 +100,8 @@ func TestQueue_ListAndDelete(t *testing.T) {
 	defer mr.Close()
 	ctx := context.Background()
 
-	t1, _ := q.Push(ctx, "echo", "1")
-	t2, _ := q.Push(ctx, "echo", "2")
+	t1, _ := q.Push(ctx, "echo", json.RawMessage(`"1"`), model.EnqueueOptions{})
+	t2, _ := q.Push(ctx, "echo", json.RawMessage(`"2"`), model.EnqueueOptions{})
 
 	list, err := q.List(ctx)
 	require.NoError(t, err)
@@ -113,3 +119,414 @@ func TestQueue_ListAndDelete(t *testing.T) {
 	assert.NotNil(t, remainingTask)
 	assert.Equal(t, t2.ID, remainingTask.ID)
 }
+
+func TestQueue_PopMovesToProcessing(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+
+	inFlight, _ := mr.List(processingKey)
+	assert.Equal(t, []string{tsk.ID}, inFlight)
+	assert.True(t, mr.Exists(leaseKey))
+
+	err = q.Ack(ctx, popped)
+	require.NoError(t, err)
+
+	assert.False(t, mr.Exists(processingKey))
+	assert.False(t, mr.Exists(leaseKey))
+}
+
+func TestQueue_Nack(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	first, _ := q.Push(ctx, "echo", json.RawMessage(`"1"`), model.EnqueueOptions{})
+	second, _ := q.Push(ctx, "echo", json.RawMessage(`"2"`), model.EnqueueOptions{})
+
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, popped.ID)
+
+	popped.Status = model.StatusProcessing
+	err = q.Nack(ctx, popped)
+	require.NoError(t, err)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{first.ID, second.ID}, pending)
+	assert.False(t, mr.Exists(processingKey))
+
+	stored, err := q.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPending, stored.Status)
+	assert.Equal(t, 0, stored.Retries)
+}
+
+func TestQueue_PopDeletedTask(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	require.NoError(t, q.Delete(ctx, tsk.ID))
+
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	assert.NoError(t, err)
+	assert.Nil(t, popped)
+	assert.False(t, mr.Exists(processingKey))
+}
+
+func TestQueue_PopStrictPriority(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	low, _ := q.Push(ctx, "echo", json.RawMessage(`"low"`), model.EnqueueOptions{Priority: model.PriorityLow})
+	def, _ := q.Push(ctx, "echo", json.RawMessage(`"default"`), model.EnqueueOptions{})
+	high, _ := q.Push(ctx, "echo", json.RawMessage(`"high"`), model.EnqueueOptions{Priority: model.PriorityHigh})
+
+	assert.Equal(t, model.PriorityDefault, def.Priority)
+	highList, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityHigh, "echo"))
+	assert.Equal(t, []string{high.ID}, highList)
+
+	for _, want := range []*model.Task{high, def, low} {
+		popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+		require.NoError(t, err)
+		require.NotNil(t, popped)
+		assert.Equal(t, want.ID, popped.ID)
+	}
+}
+
+func TestQueue_PriorityOrder_Weighted(t *testing.T) {
+	q, mr := setupTestQueue(t, WithPriorityWeights(map[model.Priority]int{
+		model.PriorityHigh:    1000000,
+		model.PriorityDefault: 1,
+		model.PriorityLow:     1,
+	}))
+	defer mr.Close()
+
+	order := q.priorityOrder()
+	assert.ElementsMatch(t, model.Priorities, order)
+	assert.Equal(t, model.PriorityHigh, order[0])
+}
+
+func TestQueue_RetryKeepsPriority(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	tsk, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{Priority: model.PriorityLow})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+
+	require.NoError(t, q.Retry(ctx, popped, 0))
+
+	lowList, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityLow, "echo"))
+	assert.Equal(t, []string{tsk.ID}, lowList)
+}
+
+func TestQueue_PushIdempotent(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	first, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+
+	second, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-1"})
+	assert.ErrorIs(t, err, model.ErrDuplicateRequest)
+	require.NotNil(t, second)
+	assert.Equal(t, first.ID, second.ID)
+
+	other, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-2"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{first.ID, other.ID}, pending)
+}
+
+func TestQueue_PushIdempotent_WindowExpired(t *testing.T) {
+	q, mr := setupTestQueue(t, WithIdempotencyWindow(time.Minute))
+	defer mr.Close()
+	ctx := context.Background()
+
+	first, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+
+	mr.FastForward(2 * time.Minute)
+
+	second, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+}
+
+func TestQueue_PushIdempotent_OriginalDeleted(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	first, _ := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-1"})
+	require.NoError(t, q.Delete(ctx, first.ID))
+
+	second, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+}
+
+func TestQueue_PushUnique(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	first, err := q.Push(ctx, "reindex", json.RawMessage(`{"customer":1}`), model.EnqueueOptions{Unique: true})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.UniqueKey)
+
+	dup, err := q.Push(ctx, "reindex", json.RawMessage(`{"customer":1}`), model.EnqueueOptions{Unique: true})
+	assert.ErrorIs(t, err, model.ErrDuplicateTask)
+	require.NotNil(t, dup)
+	assert.Equal(t, first.ID, dup.ID)
+
+	// Другой payload или тип — другой ключ уникальности
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`{"customer":2}`), model.EnqueueOptions{Unique: true})
+	require.NoError(t, err)
+	_, err = q.Push(ctx, "export", json.RawMessage(`{"customer":1}`), model.EnqueueOptions{Unique: true})
+	require.NoError(t, err)
+
+	counts, err := q.PendingByType(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"reindex": 2, "export": 1}, counts)
+}
+
+func TestQueue_PushUnique_ReleasedOnAck(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	opts := model.EnqueueOptions{Unique: true, UniqueKey: "customer-1"}
+	_, err := q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	require.NoError(t, err)
+
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`"b"`), opts)
+	assert.ErrorIs(t, err, model.ErrDuplicateTask)
+
+	popped, err := q.Pop(ctx, time.Second, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NoError(t, q.Ack(ctx, popped))
+
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`"b"`), opts)
+	assert.NoError(t, err)
+}
+
+func TestQueue_PushUnique_ReleasedOnDeadLetterAndDelete(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	opts := model.EnqueueOptions{Unique: true}
+	first, _ := q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	require.NoError(t, q.DeadLetter(ctx, first))
+
+	second, err := q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	require.NoError(t, err)
+	require.NoError(t, q.Delete(ctx, second.ID))
+
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	assert.NoError(t, err)
+}
+
+func TestQueue_PushUnique_TTL(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	opts := model.EnqueueOptions{Unique: true, UniqueTTL: time.Minute}
+	_, err := q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	require.NoError(t, err)
+
+	mr.FastForward(2 * time.Minute)
+
+	_, err = q.Push(ctx, "reindex", json.RawMessage(`"a"`), opts)
+	assert.NoError(t, err)
+}
+
+func TestQueue_PushWithPolicy(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	maxRetry := 7
+	policy := &model.ExecutionPolicy{MaxRetry: &maxRetry, Timeout: model.Duration(5 * time.Minute)}
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{Policy: policy})
+	require.NoError(t, err)
+	assert.Equal(t, 7, task.MaxRetry)
+
+	stored, err := q.Get(ctx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.Policy)
+	assert.Equal(t, model.Duration(5*time.Minute), stored.Policy.Timeout)
+}
+
+func TestQueue_ExtendLease(t *testing.T) {
+	q, mr := setupTestQueue(t, WithVisibilityTimeout(time.Minute))
+	defer mr.Close()
+	ctx := context.Background()
+
+	_, _ = q.Push(ctx, "echo", json.RawMessage(`"data"`), model.EnqueueOptions{})
+	task, err := q.Pop(ctx, time.Second, testQueues, testTypes)
+	require.NoError(t, err)
+
+	before, _ := mr.ZScore(leaseKey, task.ID)
+	require.NoError(t, q.ExtendLease(ctx, task, 10*time.Minute))
+	after, _ := mr.ZScore(leaseKey, task.ID)
+	assert.Greater(t, after-before, float64(9*time.Minute/time.Millisecond))
+
+	// Аренда не укорачивается
+	require.NoError(t, q.ExtendLease(ctx, task, 0))
+	unchanged, _ := mr.ZScore(leaseKey, task.ID)
+	assert.Equal(t, after, unchanged)
+}
+
+func TestQueue_PendingByType(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	_, _ = q.Push(ctx, "echo", json.RawMessage(`"1"`), model.EnqueueOptions{})
+	_, _ = q.Push(ctx, "echo", json.RawMessage(`"2"`), model.EnqueueOptions{Priority: model.PriorityHigh})
+	_, _ = q.Push(ctx, "sum", json.RawMessage(`[1]`), model.EnqueueOptions{Priority: model.PriorityLow})
+	_, _ = q.Push(ctx, "sum", json.RawMessage(`[2]`), model.EnqueueOptions{RunAt: time.Now().Add(time.Hour)})
+
+	counts, err := q.PendingByType(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"echo": 2, "sum": 1}, counts)
+}
+
+func TestQueue_PopOnlyRequestedTypes(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	resize, _ := q.Push(ctx, "resize", json.RawMessage(`"a.png"`), model.EnqueueOptions{})
+	echo, _ := q.Push(ctx, "echo", json.RawMessage(`"hi"`), model.EnqueueOptions{})
+
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, []string{"echo"})
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, echo.ID, popped.ID)
+
+	// resize не зарегистрирован у этого пула и остается в своей очереди
+	popped, err = q.Pop(ctx, 100*time.Millisecond, testQueues, []string{"echo"})
+	require.NoError(t, err)
+	assert.Nil(t, popped)
+
+	pending, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "resize"))
+	assert.Equal(t, []string{resize.ID}, pending)
+
+	popped, err = q.Pop(ctx, 100*time.Millisecond, testQueues, nil)
+	require.NoError(t, err)
+	assert.Nil(t, popped)
+}
+
+func TestQueue_MigrateLegacyPending(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	echo, _ := q.Push(ctx, "echo", json.RawMessage(`"1"`), model.EnqueueOptions{})
+	sum, _ := q.Push(ctx, "sum", json.RawMessage(`[1]`), model.EnqueueOptions{Priority: model.PriorityHigh})
+	low, _ := q.Push(ctx, "echo", json.RawMessage(`"2"`), model.EnqueueOptions{Priority: model.PriorityLow})
+
+	// Складываем задачи в общий список, как это делала прежняя версия
+	mr.Del(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	mr.Del(pendingKey(model.DefaultQueue, model.PriorityHigh, "sum"))
+	mr.Del(pendingKey(model.DefaultQueue, model.PriorityLow, "echo"))
+	_, _ = mr.Push(legacyPendingKey, echo.ID, "deleted-task", sum.ID, low.ID)
+
+	moved, err := q.MigrateLegacyPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, moved)
+
+	assert.False(t, mr.Exists(legacyPendingKey))
+	lowList, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityLow, "echo"))
+	assert.Equal(t, []string{low.ID}, lowList)
+	echoList, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{echo.ID}, echoList)
+	sumList, _ := mr.List(pendingKey(model.DefaultQueue, model.PriorityHigh, "sum"))
+	assert.Equal(t, []string{sum.ID}, sumList)
+}
+
+func TestQueue_NamedQueues(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	def, err := q.Push(ctx, "echo", json.RawMessage(`"1"`), model.EnqueueOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultQueue, def.Queue)
+
+	email, err := q.Push(ctx, "echo", json.RawMessage(`"2"`), model.EnqueueOptions{Queue: "emails"})
+	require.NoError(t, err)
+	assert.Equal(t, "emails", email.Queue)
+
+	pending, _ := mr.List(pendingKey("emails", model.PriorityDefault, "echo"))
+	assert.Equal(t, []string{email.ID}, pending)
+
+	// Очереди опрашиваются в переданном порядке
+	popped, err := q.Pop(ctx, 100*time.Millisecond, []string{"emails", model.DefaultQueue}, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, email.ID, popped.ID)
+
+	// Пул, не обслуживающий очередь, не забирает ее задачи
+	_, err = q.Push(ctx, "echo", json.RawMessage(`"3"`), model.EnqueueOptions{Queue: "reports"})
+	require.NoError(t, err)
+	popped, err = q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+	assert.Equal(t, def.ID, popped.ID)
+	popped, err = q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	assert.Nil(t, popped)
+}
+
+func TestQueue_QueueStats(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	for range 3 {
+		_, err := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Queue: "emails"})
+		require.NoError(t, err)
+	}
+	_, err := q.Push(ctx, "echo", json.RawMessage(`"y"`), model.EnqueueOptions{})
+	require.NoError(t, err)
+
+	emails := []string{"emails"}
+	done, _ := q.Pop(ctx, 100*time.Millisecond, emails, testTypes)
+	require.NoError(t, q.Ack(ctx, done))
+	failed, _ := q.Pop(ctx, 100*time.Millisecond, emails, testTypes)
+	require.NoError(t, q.DeadLetter(ctx, failed))
+
+	stats, err := q.QueueStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, model.DefaultQueue, stats[0].Name)
+	assert.Equal(t, int64(1), stats[0].Pending)
+	assert.Zero(t, stats[0].Completed)
+
+	assert.Equal(t, "emails", stats[1].Name)
+	assert.Equal(t, int64(1), stats[1].Pending)
+	assert.Equal(t, int64(1), stats[1].Completed)
+	assert.Equal(t, int64(1), stats[1].Failed)
+	assert.InDelta(t, 0.2, stats[1].Throughput, 1e-9)
+	assert.Equal(t, model.Duration(throughputWindow), stats[1].Window)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+const scheduleColumns = `id, name, cron_expr, timezone, task_type, payload, queue, priority, enabled, next_run_at, last_run_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSchedule(row rowScanner) (*model.Schedule, error) {
+	var (
+		s       model.Schedule
+		payload string
+		lastRun sql.NullTime
+	)
+
+	err := row.Scan(&s.ID, &s.Name, &s.Cron, &s.Timezone, &s.Type, &payload, &s.Queue, &s.Priority,
+		&s.Enabled, &s.NextRunAt, &lastRun, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Payload = model.RawPayload(payload)
+
+	if lastRun.Valid {
+		s.LastRunAt = &lastRun.Time
+	}
+	return &s, nil
+}
+
+func (r *PostgresRepository) CreateSchedule(ctx context.Context, s *model.Schedule) error {
+	query := `
+		INSERT INTO schedules (` + scheduleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);`
+
+	_, err := r.db.ExecContext(ctx, query, s.ID, s.Name, s.Cron, s.Timezone, s.Type, string(s.Payload), s.Queue, s.Priority,
+		s.Enabled, s.NextRunAt, s.LastRunAt, s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetSchedule(ctx context.Context, id string) (*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = $1;`
+
+	s, err := scanSchedule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get schedule: %w", err)
+	}
+	return s, nil
+}
+
+func (r *PostgresRepository) ListSchedules(ctx context.Context) ([]*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules ORDER BY created_at;`
+	return r.querySchedules(ctx, query)
+}
+
+// DueSchedules возвращает включенные расписания, время срабатывания которых наступило.
+func (r *PostgresRepository) DueSchedules(ctx context.Context, now time.Time) ([]*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE enabled AND next_run_at <= $1 ORDER BY next_run_at;`
+	return r.querySchedules(ctx, query, now)
+}
+
+func (r *PostgresRepository) querySchedules(ctx context.Context, query string, args ...any) ([]*model.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*model.Schedule, 0)
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, rows.Err()
+}
+
+// UpdateSchedule возвращает false, если расписание не найдено.
+func (r *PostgresRepository) UpdateSchedule(ctx context.Context, s *model.Schedule) (bool, error) {
+	query := `
+		UPDATE schedules
+		SET name = $2, cron_expr = $3, timezone = $4, task_type = $5, payload = $6,
+			queue = $7, priority = $8, enabled = $9, next_run_at = $10, updated_at = $11
+		WHERE id = $1;`
+
+	res, err := r.db.ExecContext(ctx, query, s.ID, s.Name, s.Cron, s.Timezone, s.Type, string(s.Payload),
+		s.Queue, s.Priority, s.Enabled, s.NextRunAt, s.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("update schedule: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *PostgresRepository) DeleteSchedule(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM schedules WHERE id = $1;`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete schedule: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *PostgresRepository) MarkScheduleRun(ctx context.Context, id string, runAt, next time.Time) error {
+	query := `UPDATE schedules SET last_run_at = $2, next_run_at = $3 WHERE id = $1;`
+
+	if _, err := r.db.ExecContext(ctx, query, id, runAt, next); err != nil {
+		return fmt.Errorf("mark schedule run: %w", err)
+	}
+	return nil
+}
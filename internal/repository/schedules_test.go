@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/podushkina/taskqueue/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_PostgresRepository_Schedules(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("DB_DSN env var is not set, skipping integration test")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed connection: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("postgres is configured but not responding: %v", err)
+	}
+
+	ctx := context.Background()
+
+	err = migrations.Run(db)
+	require.NoError(t, err)
+
+	repo := NewPostgresRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	s := &model.Schedule{
+		ID:        "schedule-uuid-99999",
+		Name:      "nightly",
+		Cron:      "0 3 * * *",
+		Timezone:  "UTC",
+		Type:      "echo",
+		Payload:   json.RawMessage(`"hello"`),
+		Queue:     "reports",
+		Priority:  model.PriorityDefault,
+		Enabled:   true,
+		NextRunAt: now.Add(-time.Second),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, _ = db.ExecContext(ctx, "DELETE FROM schedules WHERE id = $1", s.ID)
+
+	require.NoError(t, repo.CreateSchedule(ctx, s))
+
+	stored, err := repo.GetSchedule(ctx, s.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "nightly", stored.Name)
+	assert.Equal(t, "reports", stored.Queue)
+	assert.Nil(t, stored.LastRunAt)
+
+	due, err := repo.DueSchedules(ctx, now)
+	require.NoError(t, err)
+	assert.NotEmpty(t, due)
+
+	next := now.Add(24 * time.Hour)
+	require.NoError(t, repo.MarkScheduleRun(ctx, s.ID, now, next))
+
+	stored, err = repo.GetSchedule(ctx, s.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastRunAt)
+	assert.True(t, next.Equal(stored.NextRunAt))
+
+	s.Enabled = false
+	found, err := repo.UpdateSchedule(ctx, s)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = repo.DeleteSchedule(ctx, s.ID)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	missing, err := repo.GetSchedule(ctx, s.ID)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestQueue_TryLock(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	ok, err := q.TryLock(ctx, "schedule:1:100", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = q.TryLock(ctx, "schedule:1:100", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	mr.FastForward(time.Minute)
+
+	ok, err = q.TryLock(ctx, "schedule:1:100", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestQueue_Unlock(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	ok, err := q.TryLock(ctx, "schedule:1:100", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, q.Unlock(ctx, "schedule:1:100"))
+
+	ok, err = q.TryLock(ctx, "schedule:1:100", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
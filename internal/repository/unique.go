@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const uniquePrefix = "taskqueue:unique:"
+
+// unlockUniqueScript снимает блокировку уникальности, только если она
+// принадлежит переданной задаче.
+var unlockUniqueScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// uniqueKey строит ключ уникальности в пределах типа задачи. Без явного ключа
+// используется хеш payload без учета пробелов между токенами JSON.
+func uniqueKey(taskType string, payload json.RawMessage, key string) string {
+	if key == "" {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, payload); err != nil {
+			buf.Reset()
+			buf.Write(payload)
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		key = hex.EncodeToString(sum[:])
+	}
+	return taskType + ":" + key
+}
+
+// releaseUnique освобождает ключ уникальности, когда задача завершена, упала
+// окончательно или удалена.
+func (q *RedisQueue) releaseUnique(ctx context.Context, t *model.Task) error {
+	if t.UniqueKey == "" {
+		return nil
+	}
+	if err := unlockUniqueScript.Run(ctx, q.client, []string{uniquePrefix + t.UniqueKey}, t.ID).Err(); err != nil {
+		return fmt.Errorf("release unique key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const finishedChannelPrefix = "taskqueue:finished:"
+
+// publishFinished оповещает ожидающих задачу клиентов, что она завершена или
+// удалена. Публикуется только ID: ожидающий сам перечитывает задачу.
+func publishFinished(ctx context.Context, pipe redis.Pipeliner, id string) {
+	pipe.Publish(ctx, finishedChannelPrefix+id, id)
+}
+
+// WaitFinished ждет, пока задача не завершится, и возвращает ее. Если ctx
+// истек раньше, возвращается задача в текущем статусе; для несуществующей или
+// удаленной за время ожидания задачи возвращается nil.
+func (q *RedisQueue) WaitFinished(ctx context.Context, id string) (*model.Task, error) {
+	// Подписка должна быть активна до чтения статуса, иначе завершение между
+	// чтением и подпиской будет пропущено
+	finished := q.listen(ctx, finishedChannelPrefix+id)
+
+	t, err := q.Get(ctx, id)
+	if err != nil || t == nil || t.Status.Finished() {
+		return t, err
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-finished:
+	}
+	return q.Get(context.WithoutCancel(ctx), id)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_WaitFinishedWakesOnAck(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	popped, err := q.Pop(ctx, 100*time.Millisecond, testQueues, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		popped.Status = model.StatusCompleted
+		_ = q.Update(ctx, popped)
+		_ = q.Ack(ctx, popped)
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	res, err := q.WaitFinished(waitCtx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, model.StatusCompleted, res.Status)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestQueue_WaitFinishedTimeout(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	res, err := q.WaitFinished(waitCtx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, model.StatusPending, res.Status)
+}
+
+func TestQueue_WaitFinishedAlreadyFinished(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	_, err := q.Cancel(ctx, task.ID)
+	require.NoError(t, err)
+
+	res, err := q.WaitFinished(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusCancelled, res.Status)
+
+	res, err = q.WaitFinished(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestQueue_WaitFinishedWakesOnDelete(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = q.Delete(ctx, task.ID)
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := q.WaitFinished(waitCtx, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
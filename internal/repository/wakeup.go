@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// wakeupChannel получает сообщение всякий раз, когда в списках pending
+// появляются задачи или снимается пауза. Pop ждет его вместо частого опроса.
+const wakeupChannel = "taskqueue:wakeup"
+
+// waker рассылает оповещения из wakeupChannel всем ожидающим Pop. Подписка
+// оформляется при первом ожидании и живет до Close.
+type waker struct {
+	once sync.Once
+	mu   sync.Mutex
+	ch   chan struct{}
+	stop context.CancelFunc
+}
+
+// wakeups возвращает канал, который закроется при следующем оповещении. Канал
+// нужно получить до опроса списков, иначе оповещение между опросом и
+// ожиданием будет потеряно.
+func (q *RedisQueue) wakeups() <-chan struct{} {
+	q.wake.once.Do(q.subscribeWakeups)
+
+	q.wake.mu.Lock()
+	defer q.wake.mu.Unlock()
+	return q.wake.ch
+}
+
+func (q *RedisQueue) subscribeWakeups() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := q.client.Subscribe(ctx, wakeupChannel)
+
+	// Дожидаемся подтверждения подписки, чтобы не пропустить оповещения сразу
+	// после первого опроса. Если Redis не ответил, Pop обойдется опросом.
+	confirmCtx, confirmCancel := context.WithTimeout(ctx, popPollInterval)
+	_, _ = sub.Receive(confirmCtx)
+	confirmCancel()
+
+	q.wake.mu.Lock()
+	q.wake.ch = make(chan struct{})
+	q.wake.stop = cancel
+	q.wake.mu.Unlock()
+
+	go func() {
+		defer sub.Close()
+
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-msgs:
+				if !ok {
+					return
+				}
+				q.wake.mu.Lock()
+				close(q.wake.ch)
+				q.wake.ch = make(chan struct{})
+				q.wake.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (q *RedisQueue) stopWakeups() {
+	q.wake.mu.Lock()
+	defer q.wake.mu.Unlock()
+	if q.wake.stop != nil {
+		q.wake.stop()
+	}
+}
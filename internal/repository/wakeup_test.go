@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// popAsync запускает Pop с долгим таймаутом и возвращает канал с результатом.
+func popAsync(q *RedisQueue, queues []string) <-chan *model.Task {
+	res := make(chan *model.Task, 1)
+	go func() {
+		t, _ := q.Pop(context.Background(), 5*time.Second, queues, testTypes)
+		res <- t
+	}()
+	return res
+}
+
+func TestQueue_PopWakesOnPush(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	defer q.Close()
+
+	res := popAsync(q, testQueues)
+	// Pop успевает найти очереди пустыми и подписаться на оповещения
+	time.Sleep(50 * time.Millisecond)
+
+	task, err := q.Push(context.Background(), "echo", json.RawMessage(`"x"`), model.EnqueueOptions{})
+	require.NoError(t, err)
+
+	select {
+	case popped := <-res:
+		require.NotNil(t, popped)
+		assert.Equal(t, task.ID, popped.ID)
+	case <-time.After(popPollInterval / 2):
+		t.Fatal("Pop was not woken up by Push")
+	}
+}
+
+func TestQueue_PopWakesOnResume(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	defer q.Close()
+	ctx := context.Background()
+
+	require.NoError(t, q.PauseQueue(ctx, "emails"))
+	task, err := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Queue: "emails"})
+	require.NoError(t, err)
+
+	res := popAsync(q, []string{"emails"})
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, q.ResumeQueue(ctx, "emails"))
+
+	select {
+	case popped := <-res:
+		require.NotNil(t, popped)
+		assert.Equal(t, task.ID, popped.ID)
+	case <-time.After(popPollInterval / 2):
+		t.Fatal("Pop was not woken up by ResumeQueue")
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	workerPrefix = "taskqueue:worker:"
+	workersKey   = "taskqueue:workers"
+)
+
+// Heartbeat сохраняет состояние воркеров с TTL. Воркер, переставший
+// отправлять heartbeat, пропадает из реестра по истечении ttl.
+func (q *RedisQueue) Heartbeat(ctx context.Context, workers []model.WorkerInfo, ttl time.Duration) error {
+	now := time.Now()
+
+	pipe := q.client.TxPipeline()
+	for _, w := range workers {
+		w.LastSeen = now
+		data, err := json.Marshal(w)
+		if err != nil {
+			return fmt.Errorf("marshal worker: %w", err)
+		}
+		pipe.Set(ctx, workerPrefix+w.ID, data, ttl)
+		pipe.ZAdd(ctx, workersKey, redis.Z{Score: float64(now.UnixMilli()), Member: w.ID})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("heartbeat: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorkers убирает воркеры из реестра при штатной остановке пула.
+func (q *RedisQueue) RemoveWorkers(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(ids))
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		keys[i] = workerPrefix + id
+		members[i] = id
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, workersKey, members...)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("remove workers: %w", err)
+	}
+	return nil
+}
+
+// ListWorkers возвращает живые воркеры, отсортированные по ID. Записи воркеров
+// с истекшим heartbeat удаляются из индекса.
+func (q *RedisQueue) ListWorkers(ctx context.Context) ([]model.WorkerInfo, error) {
+	ids, err := q.client.ZRange(ctx, workersKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list workers: %w", err)
+	}
+
+	workers := make([]model.WorkerInfo, 0, len(ids))
+	if len(ids) == 0 {
+		return workers, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = workerPrefix + id
+	}
+
+	values, err := q.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fetch workers: %w", err)
+	}
+
+	now := time.Now()
+	var stale []any
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			stale = append(stale, ids[i])
+			continue
+		}
+
+		var w model.WorkerInfo
+		if err := json.Unmarshal([]byte(data), &w); err != nil {
+			continue
+		}
+		w.Stalled = w.Task != nil && now.After(w.Task.Deadline)
+		workers = append(workers, w)
+	}
+
+	if len(stale) > 0 {
+		q.client.ZRem(ctx, workersKey, stale...)
+	}
+
+	slices.SortFunc(workers, func(a, b model.WorkerInfo) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return workers, nil
+}
+
+// InFlight возвращает задачи с действующей арендой в порядке истечения аренды.
+func (q *RedisQueue) InFlight(ctx context.Context) ([]model.InFlightTask, error) {
+	leases, err := q.client.ZRangeWithScores(ctx, leaseKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list leases: %w", err)
+	}
+
+	ids := make([]string, len(leases))
+	for i, l := range leases {
+		ids[i] = l.Member.(string)
+	}
+
+	tasks, err := q.getMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*model.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	inFlight := make([]model.InFlightTask, 0, len(leases))
+	for i, l := range leases {
+		t, ok := byID[ids[i]]
+		if !ok {
+			continue
+		}
+		inFlight = append(inFlight, model.InFlightTask{
+			TaskID:         t.ID,
+			Type:           t.Type,
+			Queue:          t.QueueName(),
+			LeaseExpiresAt: time.UnixMilli(int64(l.Score)),
+		})
+	}
+	return inFlight, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_WorkerRegistry(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	idle := model.WorkerInfo{ID: "host:1:0", Host: "host", PID: 1, WorkerID: 0, StartedAt: now}
+	stuck := model.WorkerInfo{ID: "host:1:1", Host: "host", PID: 1, WorkerID: 1, StartedAt: now,
+		Task: &model.WorkerTask{ID: "t1", Type: "slow", StartedAt: now.Add(-time.Hour), Deadline: now.Add(-time.Minute)}}
+	gone := model.WorkerInfo{ID: "other:2:0", Host: "other", PID: 2}
+
+	require.NoError(t, q.Heartbeat(ctx, []model.WorkerInfo{stuck, idle}, time.Minute))
+	require.NoError(t, q.Heartbeat(ctx, []model.WorkerInfo{gone}, time.Second))
+
+	workers, err := q.ListWorkers(ctx)
+	require.NoError(t, err)
+	require.Len(t, workers, 3)
+
+	// Heartbeat воркера истек
+	mr.FastForward(2 * time.Second)
+
+	workers, err = q.ListWorkers(ctx)
+	require.NoError(t, err)
+	require.Len(t, workers, 2)
+	assert.Equal(t, "host:1:0", workers[0].ID)
+	assert.False(t, workers[0].Stalled)
+	assert.False(t, workers[0].LastSeen.IsZero())
+	assert.Equal(t, "host:1:1", workers[1].ID)
+	assert.True(t, workers[1].Stalled)
+
+	members, _ := mr.ZMembers(workersKey)
+	assert.NotContains(t, members, "other:2:0")
+
+	require.NoError(t, q.RemoveWorkers(ctx, []string{"host:1:0", "host:1:1"}))
+	workers, err = q.ListWorkers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, workers)
+}
+
+func TestQueue_InFlight(t *testing.T) {
+	q, mr := setupTestQueue(t)
+	defer mr.Close()
+	ctx := context.Background()
+
+	task, _ := q.Push(ctx, "echo", json.RawMessage(`"x"`), model.EnqueueOptions{Queue: "emails"})
+	_, _ = q.Push(ctx, "echo", json.RawMessage(`"y"`), model.EnqueueOptions{})
+
+	popped, err := q.Pop(ctx, 100*time.Millisecond, []string{"emails"}, testTypes)
+	require.NoError(t, err)
+	require.NotNil(t, popped)
+
+	inFlight, err := q.InFlight(ctx)
+	require.NoError(t, err)
+	require.Len(t, inFlight, 1)
+	assert.Equal(t, task.ID, inFlight[0].TaskID)
+	assert.Equal(t, "echo", inFlight[0].Type)
+	assert.Equal(t, "emails", inFlight[0].Queue)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), inFlight[0].LeaseExpiresAt, 5*time.Second)
+}
@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+const lockTTL = 10 * time.Minute
+
+type ScheduleStore interface {
+	DueSchedules(ctx context.Context, now time.Time) ([]*model.Schedule, error)
+	MarkScheduleRun(ctx context.Context, id string, runAt, next time.Time) error
+}
+
+type TaskEnqueuer interface {
+	Push(ctx context.Context, taskType string, payload json.RawMessage, opts model.EnqueueOptions) (*model.Task, error)
+}
+
+type Locker interface {
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, key string) error
+}
+
+// Scheduler ставит в очередь задачи периодических расписаний. При нескольких
+// репликах каждое срабатывание выполняет только та, что захватила блокировку.
+type Scheduler struct {
+	store  ScheduleStore
+	queue  TaskEnqueuer
+	locker Locker
+	logger *slog.Logger
+}
+
+func New(store ScheduleStore, q TaskEnqueuer, l Locker) *Scheduler {
+	return &Scheduler{
+		store:  store,
+		queue:  q,
+		locker: l,
+		logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+	}
+}
+
+func (s *Scheduler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+					s.logger.Error("Scheduler tick failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Tick запускает все расписания, время которых наступило к now. Пропущенные
+// срабатывания (например, пока сервер был остановлен) схлопываются в одно.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) error {
+	due, err := s.store.DueSchedules(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, sched := range due {
+		if err := s.fire(ctx, sched, now); err != nil {
+			s.logger.Error("Failed to fire schedule", "schedule_id", sched.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// fire ставит задачу одного срабатывания расписания. Ключ идемпотентности
+// привязан к срабатыванию, поэтому если после постановки задачи не удалось
+// обновить расписание, повторная попытка не создаст вторую задачу.
+func (s *Scheduler) fire(ctx context.Context, sched *model.Schedule, now time.Time) (err error) {
+	runKey := fmt.Sprintf("schedule:%s:%d", sched.ID, sched.NextRunAt.Unix())
+	acquired, err := s.locker.TryLock(ctx, runKey, lockTTL)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Срабатывание не завершено: отпускаем его для следующего тика
+		if unlockErr := s.locker.Unlock(context.WithoutCancel(ctx), runKey); unlockErr != nil {
+			s.logger.Error("Failed to release schedule lock", "schedule_id", sched.ID, "error", unlockErr)
+		}
+	}()
+
+	next, err := sched.NextAfter(now)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.queue.Push(ctx, sched.Type, sched.Payload, model.EnqueueOptions{
+		Queue:          sched.Queue,
+		Priority:       sched.Priority,
+		IdempotencyKey: runKey,
+	})
+	if err != nil && !errors.Is(err, model.ErrDuplicateRequest) {
+		return err
+	}
+
+	if err := s.store.MarkScheduleRun(ctx, sched.ID, now, next); err != nil {
+		return err
+	}
+
+	s.logger.Info("Schedule fired", "schedule_id", sched.ID, "task_id", t.ID, "next_run_at", next)
+	return nil
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStore struct {
+	schedules []*model.Schedule
+	markErr   error
+}
+
+func (m *mockStore) DueSchedules(ctx context.Context, now time.Time) ([]*model.Schedule, error) {
+	var due []*model.Schedule
+	for _, s := range m.schedules {
+		if s.Enabled && !s.NextRunAt.After(now) {
+			due = append(due, s)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockStore) MarkScheduleRun(ctx context.Context, id string, runAt, next time.Time) error {
+	if m.markErr != nil {
+		return m.markErr
+	}
+	for _, s := range m.schedules {
+		if s.ID == id {
+			s.LastRunAt = &runAt
+			s.NextRunAt = next
+		}
+	}
+	return nil
+}
+
+type mockQueue struct {
+	pushed  []model.EnqueueOptions
+	types   []string
+	pushErr error
+}
+
+func (m *mockQueue) Push(ctx context.Context, taskType string, payload json.RawMessage, opts model.EnqueueOptions) (*model.Task, error) {
+	if m.pushErr != nil {
+		return nil, m.pushErr
+	}
+	for _, p := range m.pushed {
+		if p.IdempotencyKey != "" && p.IdempotencyKey == opts.IdempotencyKey {
+			return &model.Task{ID: "t", Type: taskType}, model.ErrDuplicateRequest
+		}
+	}
+	m.types = append(m.types, taskType)
+	m.pushed = append(m.pushed, opts)
+	return &model.Task{ID: "t", Type: taskType}, nil
+}
+
+type mockLocker struct {
+	held map[string]bool
+}
+
+func (m *mockLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if m.held[key] {
+		return false, nil
+	}
+	m.held[key] = true
+	return true, nil
+}
+
+func (m *mockLocker) Unlock(ctx context.Context, key string) error {
+	delete(m.held, key)
+	return nil
+}
+
+func TestScheduler_FiresDueSchedule(t *testing.T) {
+	now := time.Date(2026, 8, 14, 3, 0, 0, 0, time.UTC)
+	store := &mockStore{schedules: []*model.Schedule{
+		{ID: "s1", Cron: "0 3 * * *", Timezone: "UTC", Type: "echo", Queue: "reports", Priority: model.PriorityHigh, Enabled: true, NextRunAt: now},
+		{ID: "s2", Cron: "0 4 * * *", Timezone: "UTC", Type: "sum", Enabled: true, NextRunAt: now.Add(time.Hour)},
+		{ID: "s3", Cron: "0 3 * * *", Timezone: "UTC", Type: "slow", Enabled: false, NextRunAt: now},
+	}}
+	q := &mockQueue{}
+	s := New(store, q, &mockLocker{held: map[string]bool{}})
+
+	require.NoError(t, s.Tick(context.Background(), now))
+
+	assert.Equal(t, []string{"echo"}, q.types)
+	assert.Equal(t, model.PriorityHigh, q.pushed[0].Priority)
+	assert.Equal(t, "reports", q.pushed[0].Queue)
+	assert.Equal(t, now.Add(24*time.Hour), store.schedules[0].NextRunAt)
+	require.NotNil(t, store.schedules[0].LastRunAt)
+}
+
+func TestScheduler_OnlyOneReplicaFires(t *testing.T) {
+	now := time.Date(2026, 8, 14, 3, 0, 0, 0, time.UTC)
+	sched := &model.Schedule{ID: "s1", Cron: "0 3 * * *", Timezone: "UTC", Type: "echo", Enabled: true, NextRunAt: now}
+	locker := &mockLocker{held: map[string]bool{}}
+
+	q1, q2 := &mockQueue{}, &mockQueue{}
+	// Обе реплики прочитали расписание до того, как первая обновила next_run_at
+	replica1 := New(&mockStore{schedules: []*model.Schedule{sched}}, q1, locker)
+	replica2 := New(&mockStore{schedules: []*model.Schedule{{ID: "s1", Cron: sched.Cron, Timezone: "UTC", Type: "echo", Enabled: true, NextRunAt: now}}}, q2, locker)
+
+	require.NoError(t, replica1.Tick(context.Background(), now))
+	require.NoError(t, replica2.Tick(context.Background(), now))
+
+	assert.Len(t, q1.types, 1)
+	assert.Empty(t, q2.types)
+}
+
+func TestScheduler_RetriesAfterPushFailure(t *testing.T) {
+	now := time.Date(2026, 8, 14, 3, 0, 0, 0, time.UTC)
+	store := &mockStore{schedules: []*model.Schedule{
+		{ID: "s1", Cron: "0 3 * * *", Timezone: "UTC", Type: "echo", Enabled: true, NextRunAt: now},
+	}}
+	q := &mockQueue{pushErr: errors.New("redis unavailable")}
+	s := New(store, q, &mockLocker{held: map[string]bool{}})
+
+	require.NoError(t, s.Tick(context.Background(), now))
+	assert.Empty(t, q.types)
+	assert.Equal(t, now, store.schedules[0].NextRunAt)
+
+	// Блокировка отпущена, следующий тик ставит пропущенное срабатывание
+	q.pushErr = nil
+	require.NoError(t, s.Tick(context.Background(), now.Add(time.Second)))
+	assert.Equal(t, []string{"echo"}, q.types)
+	assert.Equal(t, now.Add(24*time.Hour), store.schedules[0].NextRunAt)
+}
+
+func TestScheduler_NoDuplicateAfterMarkFailure(t *testing.T) {
+	now := time.Date(2026, 8, 14, 3, 0, 0, 0, time.UTC)
+	store := &mockStore{
+		schedules: []*model.Schedule{{ID: "s1", Cron: "0 3 * * *", Timezone: "UTC", Type: "echo", Enabled: true, NextRunAt: now}},
+		markErr:   errors.New("postgres unavailable"),
+	}
+	q := &mockQueue{}
+	s := New(store, q, &mockLocker{held: map[string]bool{}})
+
+	require.NoError(t, s.Tick(context.Background(), now))
+	require.Len(t, q.pushed, 1)
+	assert.NotEmpty(t, q.pushed[0].IdempotencyKey)
+
+	// Повтор срабатывания не создает вторую задачу, но обновляет расписание
+	store.markErr = nil
+	require.NoError(t, s.Tick(context.Background(), now.Add(time.Second)))
+	assert.Len(t, q.pushed, 1)
+	assert.Equal(t, now.Add(24*time.Hour), store.schedules[0].NextRunAt)
+}
+
+func TestScheduler_Timezone(t *testing.T) {
+	s := &model.Schedule{Cron: "0 3 * * *", Timezone: "Europe/Moscow"}
+
+	next, err := s.NextAfter(time.Date(2026, 8, 14, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC).Equal(next))
+}
+
+func TestScheduler_NeverFiringCron(t *testing.T) {
+	s := &model.Schedule{Cron: "0 0 30 2 *", Timezone: "UTC"}
+
+	_, err := s.NextAfter(time.Date(2026, 8, 14, 0, 0, 0, 0, time.UTC))
+	assert.ErrorContains(t, err, "never fires")
+}
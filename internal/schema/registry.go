@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type entry struct {
+	raw      json.RawMessage
+	compiled *jsonschema.Schema
+}
+
+// Registry хранит известные типы задач и JSON Schema их payload. Тип без схемы
+// принимается с любым payload.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]entry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]entry)}
+}
+
+// Register добавляет тип задачи. При непустой схеме payload этого типа
+// будет проверяться по ней; повторная регистрация заменяет схему.
+func (r *Registry) Register(taskType string, schema json.RawMessage) error {
+	var e entry
+	if len(schema) > 0 {
+		compiled, err := jsonschema.CompileString(taskType+".json", string(schema))
+		if err != nil {
+			return fmt.Errorf("compile schema for %s: %w", taskType, err)
+		}
+		e = entry{raw: schema, compiled: compiled}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[taskType] = e
+	return nil
+}
+
+// LoadDir регистрирует схемы из файлов <type>.json в каталоге dir.
+func (r *Registry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list schemas: %w", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read schema: %w", err)
+		}
+		taskType := strings.TrimSuffix(filepath.Base(file), ".json")
+		if err := r.Register(taskType, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Schema возвращает исходную схему типа или nil, если она не задана.
+func (r *Registry) Schema(taskType string) json.RawMessage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.types[taskType].raw
+}
+
+// Validate проверяет, что тип известен, а payload соответствует его схеме.
+// Возвращает model.ErrUnknownTaskType или *model.ValidationError.
+func (r *Registry) Validate(taskType string, payload json.RawMessage) error {
+	r.mu.RLock()
+	e, ok := r.types[taskType]
+	r.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", model.ErrUnknownTaskType, taskType)
+	}
+	if e.compiled == nil {
+		return nil
+	}
+
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	v, err := decodeJSON(payload)
+	if err != nil {
+		return &model.ValidationError{Fields: []model.FieldError{{Message: err.Error()}}}
+	}
+
+	err = e.compiled.Validate(v)
+	if err == nil {
+		return nil
+	}
+	// Как и Task.DecodePayload, принимаем JSON, переданный внутри строки
+	if s, ok := v.(string); ok {
+		if inner, decErr := decodeJSON([]byte(s)); decErr == nil {
+			err = e.compiled.Validate(inner)
+			if err == nil {
+				return nil
+			}
+		}
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return fmt.Errorf("validate payload: %w", err)
+	}
+	return &model.ValidationError{Fields: fieldErrors(ve)}
+}
+
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// fieldErrors собирает конечные ошибки валидации: промежуточные узлы вида
+// "doesn't validate with ..." ничего не сообщают о конкретном поле.
+func fieldErrors(ve *jsonschema.ValidationError) []model.FieldError {
+	if len(ve.Causes) == 0 {
+		return []model.FieldError{{Field: ve.InstanceLocation, Message: ve.Message}}
+	}
+
+	var fields []model.FieldError
+	for _, cause := range ve.Causes {
+		fields = append(fields, fieldErrors(cause)...)
+	}
+	return fields
+}
@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const emailSchema = `{
+	"type": "object",
+	"required": ["to", "subject"],
+	"properties": {
+		"to": {"type": "string", "format": "email"},
+		"subject": {"type": "string", "minLength": 1},
+		"retries": {"type": "integer", "minimum": 0}
+	}
+}`
+
+func TestRegistry_Validate(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("email", json.RawMessage(emailSchema)))
+
+	err := r.Validate("email", json.RawMessage(`{"to":"a@example.com","subject":"hi"}`))
+	assert.NoError(t, err)
+
+	err = r.Validate("email", json.RawMessage(`{"to":"a@example.com","subject":"","retries":-1}`))
+	var ve *model.ValidationError
+	require.True(t, errors.As(err, &ve))
+
+	fields := make(map[string]bool)
+	for _, f := range ve.Fields {
+		fields[f.Field] = true
+		assert.NotEmpty(t, f.Message)
+	}
+	assert.True(t, fields["/subject"])
+	assert.True(t, fields["/retries"])
+}
+
+func TestRegistry_Validate_StringEncodedJSON(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("sum", json.RawMessage(`{"type": "array", "items": {"type": "number"}}`)))
+	require.NoError(t, r.Register("reverse", json.RawMessage(`{"type": "string"}`)))
+
+	assert.NoError(t, r.Validate("sum", json.RawMessage(`"[10,20,30]"`)))
+	assert.NoError(t, r.Validate("reverse", json.RawMessage(`"[10,20,30]"`)))
+
+	var ve *model.ValidationError
+	assert.ErrorAs(t, r.Validate("sum", json.RawMessage(`"[10,\"x\"]"`)), &ve)
+	assert.ErrorAs(t, r.Validate("sum", json.RawMessage(`"10,20,30"`)), &ve)
+}
+
+func TestRegistry_Validate_MissingPayload(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("email", json.RawMessage(emailSchema)))
+
+	var ve *model.ValidationError
+	assert.True(t, errors.As(r.Validate("email", nil), &ve))
+}
+
+func TestRegistry_UnknownType(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register("echo", nil))
+
+	assert.NoError(t, r.Validate("echo", json.RawMessage(`{"anything":true}`)))
+	assert.ErrorIs(t, r.Validate("unknown", nil), model.ErrUnknownTaskType)
+}
+
+func TestRegistry_InvalidSchema(t *testing.T) {
+	r := NewRegistry()
+	assert.Error(t, r.Register("bad", json.RawMessage(`{"type": 42}`)))
+	assert.ErrorIs(t, r.Validate("bad", nil), model.ErrUnknownTaskType)
+}
+
+func TestRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "email.json"), []byte(emailSchema), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644))
+
+	r := NewRegistry()
+	require.NoError(t, r.LoadDir(dir))
+
+	assert.JSONEq(t, emailSchema, string(r.Schema("email")))
+	assert.Error(t, r.Validate("email", json.RawMessage(`{}`)))
+	assert.ErrorIs(t, r.Validate("notes", nil), model.ErrUnknownTaskType)
+}
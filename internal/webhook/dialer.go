@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress возвращается, если адрес получателя callback указывает во
+// внутреннюю сеть и не разрешен через WithAllowedNetworks.
+var ErrForbiddenAddress = errors.New("callback address is not allowed")
+
+// reservedNetworks — сети, не покрытые проверками netip.Addr, но так же
+// недоступные из интернета.
+var reservedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// WithAllowedNetworks разрешает доставку callback в перечисленные сети, даже
+// если они внутренние.
+func WithAllowedNetworks(networks ...netip.Prefix) Option {
+	return func(d *Dispatcher) {
+		d.allowed = append(d.allowed, networks...)
+	}
+}
+
+// WithTimeout задает таймаут одной попытки доставки.
+func WithTimeout(timeout time.Duration) Option {
+	return func(d *Dispatcher) {
+		if timeout > 0 {
+			d.timeout = timeout
+		}
+	}
+}
+
+// newClient создает HTTP-клиент, который проверяет адрес уже после разрешения
+// имени, при каждом соединении, включая редиректы. Так URL callback нельзя
+// направить во внутреннюю сеть ни напрямую, ни через DNS.
+func (d *Dispatcher) newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: d.timeout, Control: d.checkAddress}
+	return &http.Client{
+		Timeout: d.timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: d.timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func (d *Dispatcher) checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	addr = addr.Unmap()
+
+	for _, network := range d.allowed {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	if !publicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
+
+func publicAddr(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+const (
+	HeaderEvent     = "X-Taskqueue-Event"
+	HeaderDelivery  = "X-Taskqueue-Delivery"
+	HeaderTimestamp = "X-Taskqueue-Timestamp"
+	HeaderSignature = "X-Taskqueue-Signature"
+
+	defaultMaxAttempts = 5
+	defaultTimeout     = 10 * time.Second
+
+	claimBatchSize = 100
+	claimMargin    = 30 * time.Second
+)
+
+var defaultBackoff = model.Backoff{
+	Strategy: model.BackoffExponential,
+	Delay:    model.Duration(time.Second),
+	MaxDelay: model.Duration(time.Minute),
+	Jitter:   0.2,
+}
+
+// Payload — тело callback-запроса. Секрет callback в задаче не передается.
+type Payload struct {
+	Event model.EventType `json:"event"`
+	Task  *model.Task     `json:"task"`
+}
+
+// Store хранит ожидающие доставки callback и журнал попыток.
+type Store interface {
+	ClaimCallbacks(ctx context.Context, until time.Time, limit int) ([]model.CallbackDelivery, error)
+	FinishCallback(ctx context.Context, d model.CallbackDelivery) error
+	RetryCallback(ctx context.Context, d model.CallbackDelivery, at time.Time) error
+	RecordCallbackAttempt(ctx context.Context, id string, a model.CallbackAttempt) error
+	Get(ctx context.Context, id string) (*model.Task, error)
+}
+
+// Dispatcher доставляет callback завершенных задач из очереди доставок в
+// Store, повторяя неудачные попытки с backoff. Очередь хранится вне процесса,
+// поэтому недоставленные callback переживают перезапуск.
+type Dispatcher struct {
+	client      *http.Client
+	timeout     time.Duration
+	allowed     []netip.Prefix
+	store       Store
+	maxAttempts int
+	backoff     model.Backoff
+	logger      *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type Option func(*Dispatcher)
+
+// WithHTTPClient заменяет HTTP-клиент вместе с проверкой адресов получателей:
+// переданный клиент отвечает за нее сам.
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = c
+	}
+}
+
+// WithMaxAttempts задает число попыток доставки, включая первую.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.maxAttempts = n
+		}
+	}
+}
+
+func WithBackoff(b model.Backoff) Option {
+	return func(d *Dispatcher) {
+		d.backoff = b
+	}
+}
+
+func NewDispatcher(store Store, opts ...Option) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		timeout:     defaultTimeout,
+		store:       store,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		logger:      slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.client == nil {
+		d.client = d.newClient()
+	}
+	return d
+}
+
+// Start запускает фоновую доставку, проверяя очередь раз в interval.
+func (d *Dispatcher) Start(interval time.Duration) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.DeliverDue(d.ctx); err != nil && d.ctx.Err() == nil {
+					d.logger.Error("Callback dispatcher error", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop останавливает опрос и дожидается завершения начатых попыток. Ожидающие
+// доставки остаются в Store.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// DeliverDue выполняет по одной попытке для каждой доставки, время которой
+// наступило. Доставки одного пакета отправляются параллельно.
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	for {
+		// Доставка остается за нами, пока не истечет таймаут попытки
+		until := time.Now().Add(d.timeout + claimMargin)
+		deliveries, err := d.store.ClaimCallbacks(ctx, until, claimBatchSize)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.deliver(delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < claimBatchSize {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(delivery model.CallbackDelivery) {
+	// Итог начатой попытки сохраняем и при остановке
+	ctx := context.WithoutCancel(d.ctx)
+	log := d.logger.With("task_id", delivery.TaskID, "event", delivery.Event)
+
+	t, err := d.store.Get(ctx, delivery.TaskID)
+	if err != nil {
+		log.Error("Failed to load task for callback", "error", err)
+		return
+	}
+	if t == nil || t.Callback == nil || t.Callback.URL == "" {
+		d.finish(ctx, delivery, log)
+		return
+	}
+
+	body, err := json.Marshal(Payload{Event: delivery.Event, Task: t.Redacted()})
+	if err != nil {
+		log.Error("Failed to encode callback payload", "error", err)
+		d.finish(ctx, delivery, log)
+		return
+	}
+
+	attempt := delivery.Attempt + 1
+	a, err := d.attempt(t.Callback, delivery.Event, attempt, body)
+	if err := d.store.RecordCallbackAttempt(ctx, t.ID, a); err != nil {
+		log.Error("Failed to record callback attempt", "error", err)
+	}
+
+	if a.Delivered {
+		log.Info("Callback delivered", "attempt", attempt)
+		d.finish(ctx, delivery, log)
+		return
+	}
+	// Запрещенный адрес не станет разрешенным при повторе
+	if attempt >= d.maxAttempts || errors.Is(err, ErrForbiddenAddress) || !retryable(a.StatusCode) {
+		log.Warn("Callback delivery failed", "attempt", attempt, "status_code", a.StatusCode, "error", a.Error)
+		d.finish(ctx, delivery, log)
+		return
+	}
+
+	next := time.Now().Add(d.backoff.Next(attempt - 1))
+	if err := d.store.RetryCallback(ctx, delivery, next); err != nil {
+		log.Error("Failed to schedule callback retry", "error", err)
+	}
+}
+
+func (d *Dispatcher) finish(ctx context.Context, delivery model.CallbackDelivery, log *slog.Logger) {
+	if err := d.store.FinishCallback(ctx, delivery); err != nil {
+		log.Error("Failed to finish callback delivery", "error", err)
+	}
+}
+
+// attempt выполняет одну попытку доставки и возвращает ее запись и ошибку
+// запроса, если ответа не было.
+func (d *Dispatcher) attempt(cb *model.Callback, event model.EventType, n int, body []byte) (model.CallbackAttempt, error) {
+	start := time.Now()
+	a := model.CallbackAttempt{Attempt: n, Event: event, At: start}
+
+	code, err := d.post(cb, event, n, body)
+	a.Duration = model.Duration(time.Since(start))
+	a.StatusCode = code
+	if err != nil {
+		a.Error = err.Error()
+	} else if code < 200 || code >= 300 {
+		a.Error = fmt.Sprintf("unexpected status %d", code)
+	} else {
+		a.Delivered = true
+	}
+	return a, err
+}
+
+func (d *Dispatcher) post(cb *model.Callback, event model.EventType, n int, body []byte) (int, error) {
+	// Начатую попытку не прерываем при остановке: ее ограничивает таймаут клиента
+	req, err := http.NewRequestWithContext(context.WithoutCancel(d.ctx), http.MethodPost, cb.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "taskqueue-webhook")
+	req.Header.Set(HeaderEvent, string(event))
+	req.Header.Set(HeaderDelivery, strconv.Itoa(n))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if cb.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(cb.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// retryable сообщает, имеет ли смысл повторять попытку: при сетевой ошибке,
+// ответе 5xx, 408 и 429. Остальные 4xx означают, что получатель запрос
+// отверг, и повтор ничего не изменит.
+func retryable(code int) bool {
+	return code == 0 || code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// Sign возвращает подпись запроса: "sha256=" и HMAC-SHA256 от
+// "<timestamp>.<body>" в hex. Метка времени входит в подпись, чтобы получатель
+// мог отклонять повторно отправленные старые запросы.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,327 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockStore хранит задачи и очередь доставок в памяти.
+type mockStore struct {
+	mu         sync.Mutex
+	tasks      map[string]*model.Task
+	due        map[string]time.Time
+	deliveries map[string]model.CallbackDelivery
+	attempts   []model.CallbackAttempt
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		tasks:      make(map[string]*model.Task),
+		due:        make(map[string]time.Time),
+		deliveries: make(map[string]model.CallbackDelivery),
+	}
+}
+
+// finish сохраняет задачу и ставит доставку, как это делают Ack и DeadLetter.
+func (m *mockStore) finish(t *model.Task, event model.EventType) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[t.ID] = t
+	m.deliveries[t.ID] = model.CallbackDelivery{TaskID: t.ID, Event: event}
+	m.due[t.ID] = time.Now()
+}
+
+func (m *mockStore) ClaimCallbacks(ctx context.Context, until time.Time, limit int) ([]model.CallbackDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []model.CallbackDelivery
+	for id, at := range m.due {
+		if len(claimed) == limit {
+			break
+		}
+		if at.After(time.Now()) {
+			continue
+		}
+		m.due[id] = until
+		claimed = append(claimed, m.deliveries[id])
+	}
+	return claimed, nil
+}
+
+func (m *mockStore) FinishCallback(ctx context.Context, d model.CallbackDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deliveries[d.TaskID] == d {
+		delete(m.deliveries, d.TaskID)
+		delete(m.due, d.TaskID)
+	}
+	return nil
+}
+
+func (m *mockStore) RetryCallback(ctx context.Context, d model.CallbackDelivery, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deliveries[d.TaskID] == d {
+		d.Attempt++
+		m.deliveries[d.TaskID] = d
+		m.due[d.TaskID] = at
+	}
+	return nil
+}
+
+func (m *mockStore) RecordCallbackAttempt(ctx context.Context, id string, a model.CallbackAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts = append(m.attempts, a)
+	return nil
+}
+
+func (m *mockStore) Get(ctx context.Context, id string) (*model.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tasks[id], nil
+}
+
+func (m *mockStore) list() []model.CallbackAttempt {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.CallbackAttempt(nil), m.attempts...)
+}
+
+func (m *mockStore) pending() map[string]model.CallbackDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := make(map[string]model.CallbackDelivery, len(m.deliveries))
+	for id, d := range m.deliveries {
+		pending[id] = d
+	}
+	return pending
+}
+
+// loopback разрешает доставку на тестовые серверы httptest.
+var loopback = WithAllowedNetworks(netip.MustParsePrefix("127.0.0.0/8"))
+
+var fastBackoff = WithBackoff(model.Backoff{Strategy: model.BackoffFixed, Delay: model.Duration(10 * time.Millisecond)})
+
+func completedTask(url, secret string) *model.Task {
+	return &model.Task{
+		ID:       "1",
+		Type:     "sum",
+		Status:   model.StatusCompleted,
+		Result:   json.RawMessage(`60`),
+		Callback: &model.Callback{URL: url, Secret: secret},
+	}
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header, body: body}
+	}))
+	defer srv.Close()
+
+	store := newMockStore()
+	store.finish(completedTask(srv.URL, "s3cret"), model.EventCompleted)
+	d := NewDispatcher(store, loopback)
+	require.NoError(t, d.DeliverDue(context.Background()))
+
+	var r received
+	select {
+	case r = <-got:
+	default:
+		t.Fatal("callback was not delivered")
+	}
+	assert.Equal(t, "completed", r.header.Get(HeaderEvent))
+	assert.Equal(t, "1", r.header.Get(HeaderDelivery))
+
+	ts, err := strconv.ParseInt(r.header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("s3cret", ts, r.body), r.header.Get(HeaderSignature))
+
+	var p Payload
+	require.NoError(t, json.Unmarshal(r.body, &p))
+	assert.Equal(t, model.EventCompleted, p.Event)
+	assert.Equal(t, "1", p.Task.ID)
+	assert.JSONEq(t, `60`, string(p.Task.Result))
+	assert.Empty(t, p.Task.Callback.Secret)
+	assert.NotContains(t, string(r.body), "s3cret")
+
+	attempts := store.list()
+	require.Len(t, attempts, 1)
+	assert.True(t, attempts[0].Delivered)
+	assert.Equal(t, http.StatusOK, attempts[0].StatusCode)
+	assert.Empty(t, store.pending())
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "3", r.Header.Get(HeaderDelivery))
+		assert.Empty(t, r.Header.Get(HeaderSignature))
+	}))
+	defer srv.Close()
+
+	store := newMockStore()
+	store.finish(completedTask(srv.URL, ""), model.EventCompleted)
+	d := NewDispatcher(store, loopback, fastBackoff)
+	ctx := context.Background()
+
+	require.NoError(t, d.DeliverDue(ctx))
+	require.NoError(t, d.DeliverDue(ctx))
+	// Повтор ждет backoff
+	assert.Len(t, store.list(), 1)
+	assert.Equal(t, 1, store.pending()["1"].Attempt)
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, d.DeliverDue(ctx))
+		return len(store.list()) == 3
+	}, time.Second, 5*time.Millisecond)
+	attempts := store.list()
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[0].StatusCode)
+	assert.False(t, attempts[0].Delivered)
+	assert.NotEmpty(t, attempts[0].Error)
+	assert.True(t, attempts[2].Delivered)
+	assert.Empty(t, store.pending())
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		attempts int
+	}{
+		{"max attempts", http.StatusInternalServerError, 3},
+		{"client error", http.StatusBadRequest, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.code)
+			}))
+			defer srv.Close()
+
+			store := newMockStore()
+			task := completedTask(srv.URL, "")
+			task.Status = model.StatusFailed
+			store.finish(task, model.EventFailed)
+			d := NewDispatcher(store, loopback, fastBackoff, WithMaxAttempts(3))
+
+			// Лимит исчерпан или ответ не подлежит повтору: доставка снимается
+			require.Eventually(t, func() bool {
+				require.NoError(t, d.DeliverDue(context.Background()))
+				return len(store.pending()) == 0
+			}, time.Second, 5*time.Millisecond)
+			attempts := store.list()
+			require.Len(t, attempts, tt.attempts)
+			assert.Equal(t, model.EventFailed, attempts[0].Event)
+			for _, a := range attempts {
+				assert.False(t, a.Delivered)
+				assert.Equal(t, tt.code, a.StatusCode)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DropsDeliveryWithoutTask(t *testing.T) {
+	store := newMockStore()
+	store.finish(&model.Task{ID: "1", Status: model.StatusCompleted}, model.EventCompleted)
+	store.deliveries["2"] = model.CallbackDelivery{TaskID: "2", Event: model.EventFailed}
+	store.due["2"] = time.Now()
+
+	d := NewDispatcher(store, loopback)
+	require.NoError(t, d.DeliverDue(context.Background()))
+
+	assert.Empty(t, store.list())
+	assert.Empty(t, store.pending())
+}
+
+func TestDispatcher_StopKeepsPendingDeliveries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	store := newMockStore()
+	store.finish(completedTask(srv.URL, ""), model.EventCompleted)
+	d := NewDispatcher(store, loopback, WithBackoff(model.Backoff{Strategy: model.BackoffFixed, Delay: model.Duration(time.Hour)}))
+	d.Start(5 * time.Millisecond)
+
+	require.Eventually(t, func() bool { return len(store.list()) == 1 }, time.Second, 5*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	// Повтор остается в Store и будет выполнен после перезапуска
+	assert.Len(t, store.list(), 1)
+	assert.Equal(t, model.CallbackDelivery{TaskID: "1", Event: model.EventCompleted, Attempt: 1}, store.pending()["1"])
+}
+
+func TestDispatcher_RejectsInternalAddress(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	store := newMockStore()
+	store.finish(completedTask(srv.URL, ""), model.EventCompleted)
+	d := NewDispatcher(store, fastBackoff)
+	require.NoError(t, d.DeliverDue(context.Background()))
+
+	// Запрещенный адрес не повторяется, а до сервера запрос не доходит
+	attempts := store.list()
+	require.Len(t, attempts, 1)
+	assert.False(t, attempts[0].Delivered)
+	assert.Contains(t, attempts[0].Error, ErrForbiddenAddress.Error())
+	assert.Zero(t, calls.Load())
+	assert.Empty(t, store.pending())
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"8.8.8.8":         true,
+		"2001:4860::8888": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+	} {
+		assert.Equal(t, public, publicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку обработчика как неисправимую: задача сразу
+// попадает в DLQ без повторов.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// RetryAfter просит повторить задачу через d вместо интервала backoff.
+// Попытка по-прежнему учитывается в лимите max_retry.
+func RetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: d}
+}
+
+// retryReason возвращает задержку повтора и причину для метрики ретраев.
+func retryReason(err error, backoff time.Duration) (time.Duration, string) {
+	var ra *retryAfterError
+	switch {
+	case errors.As(err, &ra):
+		return ra.delay, "retry_after"
+	case errors.Is(err, context.DeadlineExceeded):
+		return backoff, "timeout"
+	default:
+		return backoff, "handler_error"
+	}
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+const (
+	heartbeatInterval = 5 * time.Second
+	// heartbeatTTL — через сколько воркер без heartbeat пропадает из реестра.
+	heartbeatTTL = 3 * heartbeatInterval
+)
+
+// initWorkers заполняет состояние воркеров пула перед запуском.
+func (p *Pool) initWorkers() {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	pid := os.Getpid()
+	now := time.Now()
+	queues := p.Queues()
+
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+
+	p.workers = make([]model.WorkerInfo, p.count)
+	for i := range p.workers {
+		p.workers[i] = model.WorkerInfo{
+			ID:        fmt.Sprintf("%s:%d:%d", host, pid, i),
+			Host:      host,
+			PID:       pid,
+			WorkerID:  i,
+			Queues:    queues,
+			StartedAt: now,
+		}
+	}
+}
+
+// setCurrentTask отмечает задачу, которую выполняет воркер, и сразу публикует
+// это, не дожидаясь очередного heartbeat. При t == nil воркер свободен.
+func (p *Pool) setCurrentTask(workerID int, t *model.Task, timeout time.Duration) {
+	p.workersMu.Lock()
+	if workerID < 0 || workerID >= len(p.workers) {
+		p.workersMu.Unlock()
+		return
+	}
+
+	w := &p.workers[workerID]
+	w.Task = nil
+	if t != nil {
+		now := time.Now()
+		w.Task = &model.WorkerTask{ID: t.ID, Type: t.Type, StartedAt: now, Deadline: now.Add(timeout)}
+	}
+	info := *w
+	p.workersMu.Unlock()
+
+	p.heartbeat(info)
+}
+
+func (p *Pool) heartbeat(workers ...model.WorkerInfo) {
+	if len(workers) == 0 {
+		return
+	}
+	if err := p.queue.Heartbeat(context.WithoutCancel(p.ctx), workers, heartbeatTTL); err != nil {
+		p.logger.Error("Failed to send heartbeat", "error", err)
+	}
+}
+
+func (p *Pool) workerInfos() []model.WorkerInfo {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+	return append([]model.WorkerInfo(nil), p.workers...)
+}
+
+// heartbeatLoop периодически публикует состояние всех воркеров пула.
+func (p *Pool) heartbeatLoop() {
+	defer p.wg.Done()
+
+	p.heartbeat(p.workerInfos()...)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.heartbeat(p.workerInfos()...)
+		}
+	}
+}
+
+// removeWorkers убирает воркеры остановленного пула из реестра.
+func (p *Pool) removeWorkers() {
+	infos := p.workerInfos()
+	ids := make([]string, len(infos))
+	for i, w := range infos {
+		ids[i] = w.ID
+	}
+	if err := p.queue.RemoveWorkers(context.Background(), ids); err != nil {
+		p.logger.Error("Failed to remove workers from registry", "error", err)
+	}
+}
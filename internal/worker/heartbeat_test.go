@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_HeartbeatLifecycle(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 2, WithStrictQueues("critical", "default"))
+
+	pool.Start(context.Background())
+	assert.Eventually(t, func() bool {
+		mc.mu.Lock()
+		defer mc.mu.Unlock()
+		return len(mc.heartbeats) >= 2
+	}, time.Second, 10*time.Millisecond)
+	pool.Stop()
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	w := mc.heartbeats[0]
+	assert.Equal(t, os.Getpid(), w.PID)
+	assert.Equal(t, []string{"critical", "default"}, w.Queues)
+	assert.Nil(t, w.Task)
+	assert.Len(t, mc.removedWorkers, 2)
+	assert.Contains(t, mc.removedWorkers, w.ID)
+}
+
+func TestPool_Run_PublishesCurrentTask(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	defer pool.cancel()
+	pool.initWorkers()
+
+	pool.Register("echo", func(ctx context.Context, t *model.Task) (any, error) {
+		return nil, nil
+	}, WithTimeout(time.Minute))
+
+	pool.run(0, &model.Task{ID: "42", Type: "echo", CreatedAt: time.Now()})
+
+	require.Len(t, mc.heartbeats, 2)
+	busy := mc.heartbeats[0].Task
+	require.NotNil(t, busy)
+	assert.Equal(t, "42", busy.ID)
+	assert.Equal(t, "echo", busy.Type)
+	assert.WithinDuration(t, busy.StartedAt.Add(time.Minute), busy.Deadline, time.Millisecond)
+	assert.Nil(t, mc.heartbeats[1].Task)
+}
@@ -10,49 +10,54 @@ import (
 	"github.com/podushkina/taskqueue/internal/model"
 )
 
-func Echo(ctx context.Context, t *model.Task) (string, error) {
+func Echo(ctx context.Context, t *model.Task) (any, error) {
 	time.Sleep(1 * time.Second)
-	return fmt.Sprintf("echo: %s", t.Payload), nil
+	return fmt.Sprintf("echo: %s", t.PayloadString()), nil
 }
 
-func Reverse(ctx context.Context, t *model.Task) (string, error) {
+// Схемы payload встроенных типов для валидации при постановке задачи.
+var (
+	ReverseSchema = json.RawMessage(`{"type": "string"}`)
+	SumSchema     = json.RawMessage(`{"type": "array", "items": {"type": "number"}}`)
+)
+
+func Reverse(ctx context.Context, s string) (string, error) {
 	time.Sleep(500 * time.Millisecond)
-	runes := []rune(t.Payload)
+	runes := []rune(s)
 	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
 		runes[i], runes[j] = runes[j], runes[i]
 	}
 	return string(runes), nil
 }
 
-func Sum(ctx context.Context, t *model.Task) (string, error) {
-	var numbers []float64
-	if err := json.Unmarshal([]byte(t.Payload), &numbers); err != nil {
-		return "", fmt.Errorf("invalid payload: expected JSON array of numbers")
-	}
-
+func Sum(ctx context.Context, numbers []float64) (float64, error) {
 	var sum float64
 	for _, n := range numbers {
 		sum += n
 	}
 
 	time.Sleep(300 * time.Millisecond)
-	return fmt.Sprintf("%.2f", sum), nil
+	return sum, nil
 }
 
-func Slow(ctx context.Context, t *model.Task) (string, error) {
-	select {
-	case <-time.After(5 * time.Second):
-		return "completed after 5 seconds", nil
-	case <-ctx.Done():
-		return "", ctx.Err()
+func Slow(ctx context.Context, t *model.Task) (any, error) {
+	const steps = 5
+	for i := 1; i <= steps; i++ {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		_ = ReportProgress(ctx, float64(i*100/steps), fmt.Sprintf("step %d of %d", i, steps))
 	}
+	return "completed after 5 seconds", nil
 }
 
-func Flaky(ctx context.Context, t *model.Task) (string, error) {
+func Flaky(ctx context.Context, t *model.Task) (any, error) {
 	time.Sleep(500 * time.Millisecond)
 
 	if rand.Float32() < 0.5 {
-		return "", fmt.Errorf("random failure (demo retry)")
+		return nil, fmt.Errorf("random failure (demo retry)")
 	}
 
 	return "succeeded after retry!", nil
@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+// BackoffFunc возвращает задержку перед повтором после retries неудачных попыток.
+type BackoffFunc func(retries int) time.Duration
+
+// handlerConfig описывает зарегистрированный тип задачи. Нулевые значения
+// означают, что действуют глобальные настройки пула.
+type handlerConfig struct {
+	handler     Handler
+	description string
+	schema      json.RawMessage
+	timeout     time.Duration
+	maxRetry    *int
+	backoff     BackoffFunc
+	backoffSpec *model.Backoff
+	retryable   func(error) bool
+	slots       chan struct{}
+}
+
+type HandlerOption func(*handlerConfig)
+
+// WithDescription задает описание типа для GET /task-types.
+func WithDescription(s string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.description = s
+	}
+}
+
+// WithSchema задает JSON Schema payload, по которой API проверяет задачи
+// этого типа при постановке.
+func WithSchema(schema json.RawMessage) HandlerOption {
+	return func(c *handlerConfig) {
+		c.schema = schema
+	}
+}
+
+// WithTimeout задает таймаут обработки задач этого типа.
+func WithTimeout(d time.Duration) HandlerOption {
+	return func(c *handlerConfig) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithMaxRetry задает число повторов для задач этого типа, если оно не
+// переопределено в самой задаче.
+func WithMaxRetry(n int) HandlerOption {
+	return func(c *handlerConfig) {
+		if n >= 0 {
+			c.maxRetry = &n
+		}
+	}
+}
+
+// WithBackoff задает стратегию backoff: exponential (с jitter), linear или fixed.
+func WithBackoff(b model.Backoff) HandlerOption {
+	return func(c *handlerConfig) {
+		c.backoff = b.Next
+		c.backoffSpec = &b
+	}
+}
+
+// WithBackoffFunc задает произвольную функцию расчета задержки.
+func WithBackoffFunc(f BackoffFunc) HandlerOption {
+	return func(c *handlerConfig) {
+		c.backoff = f
+		c.backoffSpec = &model.Backoff{Strategy: model.BackoffCustom}
+	}
+}
+
+// WithRetryable задает классификатор ошибок: если он возвращает false, задача
+// сразу попадает в DLQ без повторов.
+func WithRetryable(f func(error) bool) HandlerOption {
+	return func(c *handlerConfig) {
+		c.retryable = f
+	}
+}
+
+// WithConcurrency ограничивает число одновременно выполняемых задач этого типа
+// в пределах пула.
+func WithConcurrency(n int) HandlerOption {
+	return func(c *handlerConfig) {
+		if n > 0 {
+			c.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+func (c *handlerConfig) timeoutFor(t *model.Task) time.Duration {
+	if t.Policy != nil && t.Policy.Timeout > 0 {
+		return time.Duration(t.Policy.Timeout)
+	}
+	if c != nil && c.timeout > 0 {
+		return c.timeout
+	}
+	return defaultTaskTimeout
+}
+
+func (c *handlerConfig) maxRetryFor(t *model.Task) int {
+	if t.Policy != nil && t.Policy.MaxRetry != nil {
+		return *t.Policy.MaxRetry
+	}
+	if c != nil && c.maxRetry != nil {
+		return *c.maxRetry
+	}
+	return t.MaxRetry
+}
+
+func (c *handlerConfig) backoffFor(t *model.Task) time.Duration {
+	if t.Policy != nil && t.Policy.Backoff != nil {
+		return t.Policy.Backoff.Next(t.Retries)
+	}
+	if c != nil && c.backoff != nil {
+		return c.backoff(t.Retries)
+	}
+	return defaultBackoff.Next(t.Retries)
+}
+
+// describe возвращает метаданные типа с действующими значениями по умолчанию.
+func (c *handlerConfig) describe(name string) model.TaskType {
+	tt := model.TaskType{
+		Name:        name,
+		Description: c.description,
+		Schema:      c.schema,
+		Timeout:     model.Duration(defaultTaskTimeout),
+		MaxRetry:    model.DefaultMaxRetry,
+		Backoff:     defaultBackoff,
+		Concurrency: cap(c.slots),
+	}
+	if c.timeout > 0 {
+		tt.Timeout = model.Duration(c.timeout)
+	}
+	if c.maxRetry != nil {
+		tt.MaxRetry = *c.maxRetry
+	}
+	if c.backoffSpec != nil {
+		tt.Backoff = *c.backoffSpec
+	}
+	return tt
+}
+
+func (c *handlerConfig) isRetryable(err error) bool {
+	return c == nil || c.retryable == nil || c.retryable(err)
+}
@@ -2,10 +2,13 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,9 +16,20 @@ import (
 )
 
 type TaskConsumer interface {
-	Pop(ctx context.Context, timeout time.Duration) (*model.Task, error)
+	Pop(ctx context.Context, timeout time.Duration, queues, types []string) (*model.Task, error)
 	Update(ctx context.Context, t *model.Task) error
-	Retry(ctx context.Context, t *model.Task) error
+	Retry(ctx context.Context, t *model.Task, delay time.Duration) error
+	ExtendLease(ctx context.Context, t *model.Task, d time.Duration) error
+	Ack(ctx context.Context, t *model.Task) error
+	Nack(ctx context.Context, t *model.Task) error
+	DeadLetter(ctx context.Context, t *model.Task) error
+	AckCancelled(ctx context.Context, t *model.Task) error
+	CancelRequested(ctx context.Context, id string) (bool, error)
+	Cancellations(ctx context.Context) <-chan string
+	SetProgress(ctx context.Context, t *model.Task, p model.Progress) error
+	PublishEvent(ctx context.Context, e model.TaskEvent) error
+	Heartbeat(ctx context.Context, workers []model.WorkerInfo, ttl time.Duration) error
+	RemoveWorkers(ctx context.Context, ids []string) error
 }
 
 type HistoryRepository interface {
@@ -32,47 +46,138 @@ type MetricsRecorder interface {
 	IncDeadLetter(taskType string)
 }
 
-type Handler func(ctx context.Context, t *model.Task) (string, error)
+// Handler возвращает результат, сериализуемый в JSON. json.RawMessage
+// сохраняется как есть.
+type Handler func(ctx context.Context, t *model.Task) (any, error)
+
+const (
+	defaultTaskTimeout = 30 * time.Second
+	// slotWaitTimeout — сколько воркер ждет освобождения слота типа задачи,
+	// прежде чем снова обратиться к очереди.
+	slotWaitTimeout = time.Second
+	// defaultMaxRetryDelay ограничивает задержку повтора, если пулу не задан
+	// свой предел через WithMaxRetryDelay.
+	defaultMaxRetryDelay = 24 * time.Hour
+)
+
+var defaultBackoff = model.Backoff{Strategy: model.BackoffExponential, Delay: model.Duration(time.Second)}
 
 type Pool struct {
 	queue    TaskConsumer
 	repo     HistoryRepository
 	metrics  MetricsRecorder
-	handlers map[string]Handler
+	handlers map[string]*handlerConfig
 	count    int
-	wg       sync.WaitGroup
-	mu       sync.RWMutex
-	logger   *slog.Logger
+
+	queues        []Queue
+	strictQueues  bool
+	maxRetryDelay time.Duration
+
+	// running хранит функции отмены выполняющихся задач по их ID
+	running   map[string]context.CancelCauseFunc
+	runningMu sync.Mutex
+
+	workers   []model.WorkerInfo
+	workersMu sync.Mutex
+
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	logger *slog.Logger
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
-func NewPool(q TaskConsumer, repo HistoryRepository, m MetricsRecorder, count int) *Pool {
-	return &Pool{
+func NewPool(q TaskConsumer, repo HistoryRepository, m MetricsRecorder, count int, opts ...PoolOption) *Pool {
+	p := &Pool{
 		queue:    q,
 		repo:     repo,
 		metrics:  m,
-		handlers: make(map[string]Handler),
+		handlers: make(map[string]*handlerConfig),
 		count:    count,
+		queues:   defaultQueues,
+		running:  make(map[string]context.CancelCauseFunc),
 		logger:   slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+
+		maxRetryDelay: defaultMaxRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// WithMaxRetryDelay ограничивает задержку перед повтором задачи, как бы ее ни
+// рассчитали backoff, WithBackoffFunc или RetryAfter.
+func WithMaxRetryDelay(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		if d > 0 {
+			p.maxRetryDelay = d
+		}
 	}
 }
 
-func (p *Pool) Register(taskType string, handler Handler) {
+func (p *Pool) Register(taskType string, handler Handler, opts ...HandlerOption) {
+	cfg := &handlerConfig{handler: handler}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.handlers[taskType] = handler
+	p.handlers[taskType] = cfg
+}
+
+// TaskTypes возвращает метаданные зарегистрированных типов в алфавитном порядке.
+func (p *Pool) TaskTypes() []model.TaskType {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	types := make([]model.TaskType, 0, len(p.handlers))
+	for name, cfg := range p.handlers {
+		types = append(types, cfg.describe(name))
+	}
+	slices.SortFunc(types, func(a, b model.TaskType) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return types
+}
+
+// availableTypes возвращает типы, которые пул может взять в работу прямо сейчас:
+// зарегистрированные и не исчерпавшие лимит параллельности.
+func (p *Pool) availableTypes() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	types := make([]string, 0, len(p.handlers))
+	for name, cfg := range p.handlers {
+		if cfg.slots != nil && len(cfg.slots) == cap(cfg.slots) {
+			continue
+		}
+		types = append(types, name)
+	}
+	return types
+}
+
+func (p *Pool) handlerFor(taskType string) *handlerConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.handlers[taskType]
 }
 
 func (p *Pool) Start(ctx context.Context) {
 	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.initWorkers()
+
+	p.wg.Add(2)
+	go p.listenCancellations(p.queue.Cancellations(p.ctx))
+	go p.heartbeatLoop()
 
 	for i := 0; i < p.count; i++ {
 		p.wg.Add(1)
 		go p.worker(i)
 	}
-	p.logger.Info("Started workers", "count", p.count)
+	p.logger.Info("Started workers", "count", p.count, "queues", p.Queues())
 }
 
 func (p *Pool) Stop() {
@@ -80,6 +185,7 @@ func (p *Pool) Stop() {
 		p.cancel()
 	}
 	p.wg.Wait()
+	p.removeWorkers()
 	p.logger.Info("All workers stopped")
 }
 
@@ -92,7 +198,7 @@ func (p *Pool) worker(id int) {
 		case <-p.ctx.Done():
 			return
 		default:
-			t, err := p.queue.Pop(p.ctx, 1*time.Second)
+			t, err := p.queue.Pop(p.ctx, 1*time.Second, p.queueOrder(), p.availableTypes())
 			if err != nil {
 				if p.ctx.Err() == nil {
 					p.logger.Error("Pop error", "worker_id", id, "error", err)
@@ -103,13 +209,99 @@ func (p *Pool) worker(id int) {
 				continue
 			}
 
-			procCtx, cancel := context.WithTimeout(p.ctx, 30*time.Second)
-			p.process(procCtx, id, t)
-			cancel()
+			p.run(id, t)
 		}
 	}
 }
 
+// run выполняет задачу с таймаутом ее типа. Если лимит параллельности типа
+// успели исчерпать другие воркеры, задача возвращается в очередь, а воркер
+// ждет освобождения слота.
+func (p *Pool) run(workerID int, t *model.Task) {
+	cfg := p.handlerFor(t.Type)
+
+	if cfg != nil && cfg.slots != nil {
+		select {
+		case cfg.slots <- struct{}{}:
+			defer func() { <-cfg.slots }()
+		default:
+			if err := p.queue.Nack(p.ctx, t); err != nil {
+				p.logger.Error("Failed to nack task", "worker_id", workerID, "task_id", t.ID, "error", err)
+			}
+			p.waitSlot(cfg)
+			return
+		}
+	}
+
+	timeout := cfg.timeoutFor(t)
+	if err := p.queue.ExtendLease(p.ctx, t, timeout); err != nil {
+		p.logger.Error("Failed to extend lease", "worker_id", workerID, "task_id", t.ID, "error", err)
+	}
+
+	procCtx, cancel := context.WithTimeout(p.ctx, timeout)
+	defer cancel()
+	procCtx, cancelTask := context.WithCancelCause(procCtx)
+	defer cancelTask(nil)
+
+	p.track(t.ID, cancelTask)
+	defer p.untrack(t.ID)
+
+	p.setCurrentTask(workerID, t, timeout)
+	defer p.setCurrentTask(workerID, nil, 0)
+
+	procCtx = context.WithValue(procCtx, progressKey{}, p.newProgressReporter(t))
+
+	// Отмену могли запросить до того, как задача попала в running
+	if requested, err := p.queue.CancelRequested(p.ctx, t.ID); err != nil {
+		p.logger.Error("Failed to check cancellation", "worker_id", workerID, "task_id", t.ID, "error", err)
+	} else if requested {
+		cancelTask(model.ErrTaskCancelled)
+	}
+
+	p.process(procCtx, workerID, t)
+}
+
+func (p *Pool) track(id string, cancel context.CancelCauseFunc) {
+	p.runningMu.Lock()
+	defer p.runningMu.Unlock()
+	p.running[id] = cancel
+}
+
+func (p *Pool) untrack(id string) {
+	p.runningMu.Lock()
+	defer p.runningMu.Unlock()
+	delete(p.running, id)
+}
+
+// listenCancellations отменяет контекст обработки задач, отмену которых
+// запросили через API. Задачи других экземпляров пула игнорируются.
+func (p *Pool) listenCancellations(ids <-chan string) {
+	defer p.wg.Done()
+
+	for id := range ids {
+		p.runningMu.Lock()
+		cancel, ok := p.running[id]
+		p.runningMu.Unlock()
+
+		if ok {
+			p.logger.Info("Cancelling running task", "task_id", id)
+			cancel(model.ErrTaskCancelled)
+		}
+	}
+}
+
+func (p *Pool) waitSlot(cfg *handlerConfig) {
+	timer := time.NewTimer(slotWaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case cfg.slots <- struct{}{}:
+		<-cfg.slots
+	case <-timer.C:
+	case <-p.ctx.Done():
+	}
+}
+
 func (p *Pool) process(ctx context.Context, workerID int, t *model.Task) {
 	log := p.logger.With("worker_id", workerID, "task_id", t.ID, "type", t.Type)
 	log.Info("Processing task")
@@ -123,48 +315,88 @@ func (p *Pool) process(ctx context.Context, workerID int, t *model.Task) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Error("Worker recovered from panic", "panic", r)
-			p.fail(ctx, t, fmt.Sprintf("panic: %v", r), "panic")
+			closeProgress(ctx)
+			p.fail(context.WithoutCancel(ctx), t, fmt.Sprintf("panic: %v", r), "panic")
 		}
 	}()
 
+	cfg := p.handlerFor(t.Type)
+
+	// Сохраняем действующий лимит попыток, чтобы reaper учитывал его тоже
+	t.MaxRetry = cfg.maxRetryFor(t)
 	t.Status = model.StatusProcessing
 	if err := p.queue.Update(ctx, t); err != nil {
 		log.Error("Failed to set processing status", "error", err)
 	}
+	if err := p.queue.PublishEvent(ctx, model.NewTaskEvent(model.EventStarted, t)); err != nil {
+		log.Warn("Failed to publish task event", "error", err)
+	}
 
-	p.mu.RLock()
-	handler, ok := p.handlers[t.Type]
-	p.mu.RUnlock()
-
-	if !ok {
+	if cfg == nil {
 		log.Error("Unknown task type")
 		p.fail(ctx, t, fmt.Sprintf("unknown task type: %s", t.Type), "failed")
 		return
 	}
 
+	if errors.Is(context.Cause(ctx), model.ErrTaskCancelled) {
+		p.cancelled(context.WithoutCancel(ctx), t, log)
+		return
+	}
+
 	start := time.Now()
-	result, err := handler(ctx, t)
+	result, err := cfg.handler(ctx, t)
+	closeProgress(ctx)
 	if p.metrics != nil {
 		p.metrics.ObserveTaskDuration(t.Type, time.Since(start).Seconds())
 	}
 
 	if err != nil {
-		if t.Retries < t.MaxRetry {
-			p.scheduleRetry(t, err, log)
-		} else {
-			if p.metrics != nil {
-				p.metrics.IncDeadLetter(t.Type)
+		if errors.Is(context.Cause(ctx), model.ErrTaskCancelled) {
+			p.cancelled(context.WithoutCancel(ctx), t, log)
+			return
+		}
+		if p.ctx != nil && p.ctx.Err() != nil {
+			// Пул останавливается: возвращаем задачу в очередь, не расходуя попытку
+			if err := p.queue.Nack(context.Background(), t); err != nil {
+				log.Error("Failed to nack task", "error", err)
 			}
-			p.fail(ctx, t, err.Error(), "failed")
+			log.Warn("Task interrupted by shutdown, returned to queue")
+			return
+		}
+		if t.Retries < t.MaxRetry && !IsPermanent(err) && cfg.isRetryable(err) {
+			delay, reason := retryReason(err, cfg.backoffFor(t))
+			p.scheduleRetry(t, min(delay, p.maxRetryDelay), reason, err, log)
+		} else {
+			// Контекст обработки мог истечь по таймауту, а DLQ все равно нужно записать
+			p.fail(context.WithoutCancel(ctx), t, err.Error(), "failed")
 			log.Error("Task failed permanently, moved to DLQ", "error", err)
 		}
 		return
 	}
 
-	p.complete(ctx, t, result, log)
+	data, err := encodeResult(result)
+	if err != nil {
+		p.fail(context.WithoutCancel(ctx), t, fmt.Sprintf("encode result: %v", err), "failed")
+		log.Error("Failed to encode task result, moved to DLQ", "error", err)
+		return
+	}
+
+	// Обработчик мог вернуть результат на самом дедлайне: без этого Ack не
+	// прошел бы, и reaper повторил бы уже выполненную задачу
+	p.complete(context.WithoutCancel(ctx), t, data, log)
 }
 
-func (p *Pool) complete(ctx context.Context, t *model.Task, result string, log *slog.Logger) {
+func encodeResult(v any) (json.RawMessage, error) {
+	switch r := v.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		return r, nil
+	}
+	return json.Marshal(v)
+}
+
+func (p *Pool) complete(ctx context.Context, t *model.Task, result json.RawMessage, log *slog.Logger) {
 	if p.metrics != nil {
 		p.metrics.IncTasksProcessed(t.Type, "success")
 	}
@@ -172,45 +404,54 @@ func (p *Pool) complete(ctx context.Context, t *model.Task, result string, log *
 	t.Result = result
 
 	_ = p.queue.Update(ctx, t)
+	if err := p.queue.Ack(ctx, t); err != nil {
+		log.Error("Failed to ack task", "error", err)
+	}
 	if err := p.repo.SaveHistory(ctx, t); err != nil {
 		log.Error("Failed to save history", "error", err)
 	}
 	log.Info("Task completed")
 }
 
+func (p *Pool) cancelled(ctx context.Context, t *model.Task, log *slog.Logger) {
+	if p.metrics != nil {
+		p.metrics.IncTasksProcessed(t.Type, "cancelled")
+	}
+	t.Status = model.StatusCancelled
+	t.Error = model.ErrTaskCancelled.Error()
+
+	if err := p.queue.AckCancelled(ctx, t); err != nil {
+		log.Error("Failed to ack cancelled task", "error", err)
+	}
+	if err := p.repo.SaveHistory(ctx, t); err != nil {
+		log.Error("Failed to save history", "error", err)
+	}
+	log.Info("Task cancelled")
+}
+
 func (p *Pool) fail(ctx context.Context, t *model.Task, reason, metricStatus string) {
 	if p.metrics != nil {
 		p.metrics.IncTasksProcessed(t.Type, metricStatus)
+		p.metrics.IncDeadLetter(t.Type)
 	}
 	t.Status = model.StatusFailed
 	t.Error = reason
 
-	_ = p.queue.Update(ctx, t)
+	if err := p.queue.DeadLetter(ctx, t); err != nil {
+		p.logger.Error("Failed to move task to DLQ", "task_id", t.ID, "error", err)
+	}
 	if err := p.repo.SaveHistory(ctx, t); err != nil {
 		p.logger.Error("Failed to save history", "task_id", t.ID, "error", err)
 	}
 }
 
-func (p *Pool) scheduleRetry(t *model.Task, err error, log *slog.Logger) {
+func (p *Pool) scheduleRetry(t *model.Task, backoff time.Duration, reason string, err error, log *slog.Logger) {
 	if p.metrics != nil {
-		p.metrics.IncTaskRetries(t.Type, "handler_error")
+		p.metrics.IncTaskRetries(t.Type, reason)
 	}
-	backoff := time.Duration(math.Pow(2, float64(t.Retries))) * time.Second
-	log.Warn("Task failed, scheduling retry", "attempt", t.Retries+1, "backoff", backoff, "error", err)
-
-	p.wg.Add(1)
-	go func() {
-		defer p.wg.Done()
-		timer := time.NewTimer(backoff)
-		defer timer.Stop()
+	log.Warn("Task failed, scheduling retry", "attempt", t.Retries+1, "backoff", backoff, "reason", reason, "error", err)
 
-		select {
-		case <-timer.C:
-			if err := p.queue.Retry(context.Background(), t); err != nil {
-				p.logger.Error("Failed to retry task", "task_id", t.ID, "error", err)
-			}
-		case <-p.ctx.Done():
-			p.logger.Warn("Retry cancelled on shutdown", "task_id", t.ID)
-		}
-	}()
+	if err := p.queue.Retry(context.Background(), t, backoff); err != nil {
+		log.Error("Failed to schedule retry", "error", err)
+	}
 }
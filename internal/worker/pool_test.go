@@ -2,7 +2,10 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,10 +17,27 @@ import (
 type mockConsumer struct {
 	updatedTask *model.Task
 	retryCalled bool
+	retryDelay  time.Duration
+	ackCalled   bool
+	ackCtxErr   error
+	nackCalled  bool
+	deadLetter  bool
 	errOnUpdate error
+
+	cancelled       bool
+	cancelRequested bool
+	cancellations   chan string
+
+	mu       sync.Mutex
+	progress []model.Progress
+	// progressAfterFinish отмечает запись прогресса после Ack или DeadLetter
+	progressAfterFinish bool
+	events              []model.TaskEvent
+	heartbeats          []model.WorkerInfo
+	removedWorkers      []string
 }
 
-func (m *mockConsumer) Pop(ctx context.Context, timeout time.Duration) (*model.Task, error) {
+func (m *mockConsumer) Pop(ctx context.Context, timeout time.Duration, queues, types []string) (*model.Task, error) {
 	return nil, nil
 }
 
@@ -29,8 +49,83 @@ func (m *mockConsumer) Update(ctx context.Context, t *model.Task) error {
 	return nil
 }
 
-func (m *mockConsumer) Retry(ctx context.Context, t *model.Task) error {
+func (m *mockConsumer) Retry(ctx context.Context, t *model.Task, delay time.Duration) error {
 	m.retryCalled = true
+	m.retryDelay = delay
+	return nil
+}
+
+func (m *mockConsumer) ExtendLease(ctx context.Context, t *model.Task, d time.Duration) error {
+	return nil
+}
+
+func (m *mockConsumer) Ack(ctx context.Context, t *model.Task) error {
+	m.ackCalled = true
+	m.ackCtxErr = ctx.Err()
+	return nil
+}
+
+func (m *mockConsumer) Nack(ctx context.Context, t *model.Task) error {
+	m.nackCalled = true
+	return nil
+}
+
+func (m *mockConsumer) AckCancelled(ctx context.Context, t *model.Task) error {
+	m.cancelled = true
+	m.updatedTask = t
+	return nil
+}
+
+func (m *mockConsumer) CancelRequested(ctx context.Context, id string) (bool, error) {
+	return m.cancelRequested, nil
+}
+
+func (m *mockConsumer) Cancellations(ctx context.Context) <-chan string {
+	if m.cancellations != nil {
+		return m.cancellations
+	}
+	ids := make(chan string)
+	go func() {
+		<-ctx.Done()
+		close(ids)
+	}()
+	return ids
+}
+
+func (m *mockConsumer) SetProgress(ctx context.Context, t *model.Task, p model.Progress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.progress = append(m.progress, p)
+	if m.ackCalled || m.deadLetter {
+		m.progressAfterFinish = true
+	}
+	return nil
+}
+
+func (m *mockConsumer) PublishEvent(ctx context.Context, e model.TaskEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *mockConsumer) Heartbeat(ctx context.Context, workers []model.WorkerInfo, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.heartbeats = append(m.heartbeats, workers...)
+	return nil
+}
+
+func (m *mockConsumer) RemoveWorkers(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removedWorkers = ids
+	return nil
+}
+
+func (m *mockConsumer) DeadLetter(ctx context.Context, t *model.Task) error {
+	m.deadLetter = true
+	m.updatedTask = t
 	return nil
 }
 
@@ -49,14 +144,16 @@ func (m *mockHistory) SaveHistory(ctx context.Context, t *model.Task) error {
 	return nil
 }
 
-type mockMetrics struct{}
+type mockMetrics struct {
+	retryReason string
+}
 
 func (m *mockMetrics) IncActiveWorkers()                                    {}
 func (m *mockMetrics) DecActiveWorkers()                                    {}
 func (m *mockMetrics) ObserveWaitDuration(taskType string, seconds float64) {}
 func (m *mockMetrics) ObserveTaskDuration(taskType string, seconds float64) {}
 func (m *mockMetrics) IncTasksProcessed(taskType, status string)            {}
-func (m *mockMetrics) IncTaskRetries(taskType, reason string)               {}
+func (m *mockMetrics) IncTaskRetries(taskType, reason string)               { m.retryReason = reason }
 func (m *mockMetrics) IncDeadLetter(taskType string)                        {}
 
 func TestPool_Process_Success(t *testing.T) {
@@ -65,7 +162,7 @@ func TestPool_Process_Success(t *testing.T) {
 	mm := &mockMetrics{}
 	pool := NewPool(mc, mh, mm, 1)
 
-	pool.Register("success_task", func(ctx context.Context, t *model.Task) (string, error) {
+	pool.Register("success_task", func(ctx context.Context, t *model.Task) (any, error) {
 		return "computed data", nil
 	})
 
@@ -74,8 +171,88 @@ func TestPool_Process_Success(t *testing.T) {
 
 	require.NotNil(t, mc.updatedTask)
 	assert.Equal(t, model.StatusCompleted, mc.updatedTask.Status)
-	assert.Equal(t, "computed data", mc.updatedTask.Result)
+	assert.JSONEq(t, `"computed data"`, string(mc.updatedTask.Result))
 	assert.True(t, mh.saved)
+	assert.True(t, mc.ackCalled)
+
+	require.Len(t, mc.events, 1)
+	assert.Equal(t, model.EventStarted, mc.events[0].Event)
+	assert.Equal(t, model.StatusProcessing, mc.events[0].Status)
+}
+
+func TestPool_Process_StructuredResult(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+
+	RegisterTyped(pool, "sum", Sum)
+
+	for _, payload := range []string{`[10, 20, 30.5]`, `"[10, 20, 30.5]"`} {
+		tsk := &model.Task{ID: "1", Type: "sum", Payload: json.RawMessage(payload), MaxRetry: 3, CreatedAt: time.Now()}
+		pool.process(context.Background(), 1, tsk)
+
+		require.NotNil(t, mc.updatedTask)
+		assert.Equal(t, model.StatusCompleted, mc.updatedTask.Status, payload)
+		assert.JSONEq(t, `60.5`, string(mc.updatedTask.Result), payload)
+	}
+}
+
+func TestRegisterTyped(t *testing.T) {
+	type resizeIn struct {
+		URL   string `json:"url"`
+		Width int    `json:"width"`
+	}
+	type resizeOut struct {
+		Thumb string `json:"thumb"`
+	}
+
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+
+	RegisterTyped(pool, "resize", func(ctx context.Context, in resizeIn) (resizeOut, error) {
+		return resizeOut{Thumb: fmt.Sprintf("%s@%d", in.URL, in.Width)}, nil
+	})
+
+	tsk := &model.Task{ID: "1", Type: "resize", Payload: json.RawMessage(`{"url":"a.png","width":64}`), MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.Equal(t, model.StatusCompleted, tsk.Status)
+	assert.JSONEq(t, `{"thumb":"a.png@64"}`, string(tsk.Result))
+
+	bad := &model.Task{ID: "2", Type: "resize", Payload: json.RawMessage(`{"width":"wide"}`), MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, bad)
+
+	assert.False(t, mc.retryCalled)
+	assert.True(t, mc.deadLetter)
+	assert.Contains(t, bad.Error, "invalid payload")
+}
+
+func TestRegisterTyped_EmptyPayload(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+
+	RegisterTyped(pool, "reverse", Reverse)
+
+	tsk := &model.Task{ID: "1", Type: "reverse", MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.Equal(t, model.StatusCompleted, tsk.Status)
+	assert.JSONEq(t, `""`, string(tsk.Result))
+}
+
+func TestPool_Process_UnencodableResult(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+
+	pool.Register("bad_result", func(ctx context.Context, t *model.Task) (any, error) {
+		return make(chan int), nil
+	})
+
+	tsk := &model.Task{ID: "1", Type: "bad_result", MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.True(t, mc.deadLetter)
+	assert.False(t, mc.ackCalled)
+	assert.Contains(t, tsk.Error, "encode result")
 }
 
 func TestPool_Process_UnknownTaskType(t *testing.T) {
@@ -90,6 +267,7 @@ func TestPool_Process_UnknownTaskType(t *testing.T) {
 	assert.Equal(t, model.StatusFailed, mc.updatedTask.Status)
 	assert.Contains(t, mc.updatedTask.Error, "unknown task type")
 	assert.True(t, mh.saved)
+	assert.True(t, mc.deadLetter)
 }
 
 func TestPool_Process_HandlerError_WithRetry(t *testing.T) {
@@ -98,20 +276,290 @@ func TestPool_Process_HandlerError_WithRetry(t *testing.T) {
 	mm := &mockMetrics{}
 	pool := NewPool(mc, mh, mm, 1)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	pool.Start(ctx)
+	pool.Register("retry_task", func(ctx context.Context, t *model.Task) (any, error) {
+		return "", errors.New("temporary error")
+	})
+
+	tsk := &model.Task{ID: "3", Type: "retry_task", Status: model.StatusPending, Retries: 1, MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.True(t, mc.retryCalled)
+	assert.Equal(t, 2*time.Second, mc.retryDelay)
+	assert.False(t, mh.saved)
+}
+
+func TestPool_Process_HandlerError_TaskBackoff(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
 
-	pool.Register("retry_task", func(ctx context.Context, t *model.Task) (string, error) {
+	pool.Register("retry_task", func(ctx context.Context, t *model.Task) (any, error) {
 		return "", errors.New("temporary error")
 	})
 
-	tsk := &model.Task{ID: "3", Type: "retry_task", Status: model.StatusPending, Retries: 0, MaxRetry: 3, CreatedAt: time.Now()}
+	tsk := &model.Task{ID: "3", Type: "retry_task", Retries: 2, MaxRetry: 5, CreatedAt: time.Now(),
+		Policy: &model.ExecutionPolicy{Backoff: &model.Backoff{Strategy: model.BackoffLinear, Delay: model.Duration(10 * time.Second)}},
+	}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.True(t, mc.retryCalled)
+	assert.Equal(t, 30*time.Second, mc.retryDelay)
+}
+
+func TestHandlerConfig_Precedence(t *testing.T) {
+	var global *handlerConfig
+	assert.Equal(t, defaultTaskTimeout, global.timeoutFor(&model.Task{}))
+	assert.Equal(t, 2*time.Second, global.backoffFor(&model.Task{Retries: 1}))
+	assert.Equal(t, 3, global.maxRetryFor(&model.Task{MaxRetry: 3}))
+
+	cfg := &handlerConfig{}
+	WithTimeout(time.Minute)(cfg)
+	WithMaxRetry(1)(cfg)
+	WithBackoff(model.Backoff{Strategy: model.BackoffFixed, Delay: model.Duration(5 * time.Second)})(cfg)
+
+	plain := &model.Task{MaxRetry: 3, Retries: 2}
+	assert.Equal(t, time.Minute, cfg.timeoutFor(plain))
+	assert.Equal(t, 1, cfg.maxRetryFor(plain))
+	assert.Equal(t, 5*time.Second, cfg.backoffFor(plain))
+
+	maxRetry := 6
+	overridden := &model.Task{MaxRetry: 6, Retries: 2, Policy: &model.ExecutionPolicy{
+		MaxRetry: &maxRetry,
+		Timeout:  model.Duration(5 * time.Minute),
+		Backoff:  &model.Backoff{Strategy: model.BackoffLinear, Delay: model.Duration(time.Second)},
+	}}
+	assert.Equal(t, 5*time.Minute, cfg.timeoutFor(overridden))
+	assert.Equal(t, 6, cfg.maxRetryFor(overridden))
+	assert.Equal(t, 3*time.Second, cfg.backoffFor(overridden))
+}
+
+func TestPool_Process_TypeOptions(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+
+	pool.Register("custom", func(ctx context.Context, t *model.Task) (any, error) {
+		return "", errors.New("temporary error")
+	}, WithMaxRetry(5), WithBackoffFunc(func(retries int) time.Duration {
+		return time.Duration(retries) * time.Minute
+	}))
+
+	tsk := &model.Task{ID: "3", Type: "custom", Retries: 3, MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.True(t, mc.retryCalled)
+	assert.Equal(t, 3*time.Minute, mc.retryDelay)
+	assert.Equal(t, 5, tsk.MaxRetry)
+}
+
+func TestPool_Process_NotRetryable(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+	errInvalid := errors.New("invalid input")
+
+	pool.Register("strict", func(ctx context.Context, t *model.Task) (any, error) {
+		return "", errInvalid
+	}, WithRetryable(func(err error) bool { return !errors.Is(err, errInvalid) }))
+
+	tsk := &model.Task{ID: "3", Type: "strict", MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.False(t, mc.retryCalled)
+	assert.True(t, mc.deadLetter)
+}
+
+func TestPool_Process_PermanentError(t *testing.T) {
+	mc := &mockConsumer{}
+	mh := &mockHistory{}
+	pool := NewPool(mc, mh, &mockMetrics{}, 1)
+
+	RegisterTyped(pool, "sum", Sum)
+
+	tsk := &model.Task{ID: "7", Type: "sum", Payload: json.RawMessage(`"not json"`), MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(context.Background(), 1, tsk)
+
+	assert.False(t, mc.retryCalled)
+	assert.True(t, mc.deadLetter)
+	assert.Contains(t, tsk.Error, "invalid payload")
+	assert.True(t, mh.saved)
+}
+
+func TestPool_Process_RetryAfter(t *testing.T) {
+	mc := &mockConsumer{}
+	mm := &mockMetrics{}
+	pool := NewPool(mc, &mockHistory{}, mm, 1)
+
+	pool.Register("rate_limited", func(ctx context.Context, t *model.Task) (any, error) {
+		return "", RetryAfter(errors.New("429 too many requests"), 42*time.Second)
+	})
+
+	tsk := &model.Task{ID: "8", Type: "rate_limited", MaxRetry: 3, CreatedAt: time.Now()}
 	pool.process(context.Background(), 1, tsk)
 
-	time.Sleep(1050 * time.Millisecond)
+	assert.True(t, mc.retryCalled)
+	assert.Equal(t, 42*time.Second, mc.retryDelay)
+	assert.Equal(t, "retry_after", mm.retryReason)
+}
+
+func TestPool_Process_RetryDelayCapped(t *testing.T) {
+	for _, tc := range []struct {
+		opts []PoolOption
+		want time.Duration
+	}{
+		{nil, defaultMaxRetryDelay},
+		{[]PoolOption{WithMaxRetryDelay(time.Hour)}, time.Hour},
+	} {
+		mc := &mockConsumer{}
+		pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1, tc.opts...)
+		pool.Register("rate_limited", func(ctx context.Context, t *model.Task) (any, error) {
+			return "", RetryAfter(errors.New("429 too many requests"), 48*time.Hour)
+		})
+
+		tsk := &model.Task{ID: "8", Type: "rate_limited", MaxRetry: 3, CreatedAt: time.Now()}
+		pool.process(context.Background(), 1, tsk)
+
+		assert.True(t, mc.retryCalled)
+		assert.Equal(t, tc.want, mc.retryDelay)
+	}
+}
+
+func TestPool_Process_TimeoutRetryReason(t *testing.T) {
+	mc := &mockConsumer{}
+	mm := &mockMetrics{}
+	pool := NewPool(mc, &mockHistory{}, mm, 1)
+
+	pool.Register("slow", func(ctx context.Context, t *model.Task) (any, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	tsk := &model.Task{ID: "9", Type: "slow", MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(ctx, 1, tsk)
 
 	assert.True(t, mc.retryCalled)
+	assert.Equal(t, "timeout", mm.retryReason)
+}
+
+func TestPool_Process_CompletesAfterDeadline(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+
+	// Обработчик не следит за ctx и возвращает результат после дедлайна
+	pool.Register("late", func(ctx context.Context, t *model.Task) (any, error) {
+		<-ctx.Done()
+		return "done", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	pool.process(ctx, 1, &model.Task{ID: "10", Type: "late", MaxRetry: 3, CreatedAt: time.Now()})
+
+	assert.True(t, mc.ackCalled)
+	assert.NoError(t, mc.ackCtxErr)
+}
+
+func TestPermanent_Unwrap(t *testing.T) {
+	base := errors.New("bad input")
+	err := fmt.Errorf("handler: %w", Permanent(base))
+
+	assert.True(t, IsPermanent(err))
+	assert.ErrorIs(t, err, base)
+	assert.False(t, IsPermanent(base))
+	assert.Nil(t, Permanent(nil))
+}
+
+func TestPool_Run_ConcurrencyLimit(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	defer pool.cancel()
+
+	pool.Register("limited", func(ctx context.Context, t *model.Task) (any, error) {
+		return "ok", nil
+	}, WithConcurrency(1))
+
+	// Занимаем единственный слот, как будто задача уже выполняется
+	pool.handlers["limited"].slots <- struct{}{}
+
+	done := make(chan struct{})
+	go func() {
+		pool.run(1, &model.Task{ID: "1", Type: "limited", CreatedAt: time.Now()})
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	<-pool.handlers["limited"].slots
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop waiting after slot was released")
+	}
+	assert.True(t, mc.nackCalled)
+	assert.False(t, mc.ackCalled)
+}
+
+func TestPool_Run_CancelRunningTask(t *testing.T) {
+	mc := &mockConsumer{cancellations: make(chan string)}
+	mh := &mockHistory{}
+	pool := NewPool(mc, mh, &mockMetrics{}, 1)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	defer pool.cancel()
+
+	pool.wg.Add(1)
+	go pool.listenCancellations(mc.cancellations)
+
+	started := make(chan struct{})
+	pool.Register("long", func(ctx context.Context, t *model.Task) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		pool.run(1, &model.Task{ID: "42", Type: "long", MaxRetry: 3, CreatedAt: time.Now()})
+		close(done)
+	}()
+
+	<-started
+	mc.cancellations <- "unknown"
+	mc.cancellations <- "42"
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler was not cancelled")
+	}
+	assert.True(t, mc.cancelled)
+	assert.False(t, mc.retryCalled)
+	assert.False(t, mc.deadLetter)
+	assert.Equal(t, model.StatusCancelled, mc.updatedTask.Status)
+	assert.True(t, mh.saved)
+
+	close(mc.cancellations)
+	pool.wg.Wait()
+}
+
+func TestPool_Run_CancelRequestedBeforeStart(t *testing.T) {
+	mc := &mockConsumer{cancelRequested: true}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	defer pool.cancel()
+
+	called := false
+	pool.Register("echo", func(ctx context.Context, t *model.Task) (any, error) {
+		called = true
+		return nil, nil
+	})
+
+	pool.run(1, &model.Task{ID: "1", Type: "echo", CreatedAt: time.Now()})
+
+	assert.False(t, called)
+	assert.True(t, mc.cancelled)
+	assert.False(t, mc.ackCalled)
 }
 
 func TestPool_Process_HandlerError_MaxRetryReached(t *testing.T) {
@@ -120,7 +568,7 @@ func TestPool_Process_HandlerError_MaxRetryReached(t *testing.T) {
 	mm := &mockMetrics{}
 	pool := NewPool(mc, mh, mm, 1)
 
-	pool.Register("dead_task", func(ctx context.Context, t *model.Task) (string, error) {
+	pool.Register("dead_task", func(ctx context.Context, t *model.Task) (any, error) {
 		return "", errors.New("fatal error")
 	})
 
@@ -131,6 +579,31 @@ func TestPool_Process_HandlerError_MaxRetryReached(t *testing.T) {
 	assert.Equal(t, model.StatusFailed, mc.updatedTask.Status)
 	assert.Equal(t, "fatal error", mc.updatedTask.Error)
 	assert.True(t, mh.saved)
+	assert.True(t, mc.deadLetter)
+}
+
+func TestPool_Process_InterruptedByShutdown(t *testing.T) {
+	mc := &mockConsumer{}
+	mh := &mockHistory{}
+	mm := &mockMetrics{}
+	pool := NewPool(mc, mh, mm, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+
+	pool.Register("long_task", func(ctx context.Context, t *model.Task) (any, error) {
+		cancel()
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	tsk := &model.Task{ID: "7", Type: "long_task", Status: model.StatusPending, MaxRetry: 3, CreatedAt: time.Now()}
+	pool.process(pool.ctx, 1, tsk)
+	pool.Stop()
+
+	assert.True(t, mc.nackCalled)
+	assert.False(t, mc.retryCalled)
+	assert.False(t, mh.saved)
 }
 
 func TestPool_Process_QueueUpdateError(t *testing.T) {
@@ -139,7 +612,7 @@ func TestPool_Process_QueueUpdateError(t *testing.T) {
 	mm := &mockMetrics{}
 	pool := NewPool(mc, mh, mm, 1)
 
-	pool.Register("task", func(ctx context.Context, t *model.Task) (string, error) {
+	pool.Register("task", func(ctx context.Context, t *model.Task) (any, error) {
 		return "ok", nil
 	})
 
@@ -155,7 +628,7 @@ func TestPool_Process_HistorySaveError(t *testing.T) {
 	mm := &mockMetrics{}
 	pool := NewPool(mc, mh, mm, 1)
 
-	pool.Register("task", func(ctx context.Context, t *model.Task) (string, error) {
+	pool.Register("task", func(ctx context.Context, t *model.Task) (any, error) {
 		return "ok", nil
 	})
 
@@ -165,10 +638,72 @@ func TestPool_Process_HistorySaveError(t *testing.T) {
 	})
 }
 
+func TestPool_TaskTypes(t *testing.T) {
+	pool := NewPool(&mockConsumer{}, &mockHistory{}, &mockMetrics{}, 1)
+	h := func(ctx context.Context, t *model.Task) (any, error) { return nil, nil }
+
+	pool.Register("slow", h, WithDescription("long job"), WithTimeout(time.Minute), WithConcurrency(2),
+		WithBackoffFunc(func(int) time.Duration { return time.Second }))
+	RegisterTyped(pool, "sum", Sum, WithSchema(SumSchema), WithMaxRetry(1))
+
+	types := pool.TaskTypes()
+	require.Len(t, types, 2)
+
+	slow, sum := types[0], types[1]
+	assert.Equal(t, "slow", slow.Name)
+	assert.Equal(t, "long job", slow.Description)
+	assert.Equal(t, model.Duration(time.Minute), slow.Timeout)
+	assert.Equal(t, model.DefaultMaxRetry, slow.MaxRetry)
+	assert.Equal(t, model.BackoffCustom, slow.Backoff.Strategy)
+	assert.Equal(t, 2, slow.Concurrency)
+
+	assert.Equal(t, "sum", sum.Name)
+	assert.JSONEq(t, string(SumSchema), string(sum.Schema))
+	assert.Equal(t, model.Duration(defaultTaskTimeout), sum.Timeout)
+	assert.Equal(t, 1, sum.MaxRetry)
+	assert.Equal(t, defaultBackoff, sum.Backoff)
+}
+
+func TestPool_AvailableTypes(t *testing.T) {
+	pool := NewPool(&mockConsumer{}, &mockHistory{}, &mockMetrics{}, 1)
+	h := func(ctx context.Context, t *model.Task) (any, error) { return nil, nil }
+
+	pool.Register("echo", h)
+	pool.Register("slow", h, WithConcurrency(1))
+	assert.ElementsMatch(t, []string{"echo", "slow"}, pool.availableTypes())
+
+	// Исчерпавший лимит тип не запрашивается из очереди
+	pool.handlers["slow"].slots <- struct{}{}
+	assert.Equal(t, []string{"echo"}, pool.availableTypes())
+}
+
+func TestPool_QueueOrder(t *testing.T) {
+	pool := NewPool(&mockConsumer{}, &mockHistory{}, &mockMetrics{}, 1)
+	assert.Equal(t, []string{model.DefaultQueue}, pool.queueOrder())
+
+	strict := NewPool(&mockConsumer{}, &mockHistory{}, &mockMetrics{}, 1,
+		WithStrictQueues("critical", "emails", "default"))
+	for range 10 {
+		assert.Equal(t, []string{"critical", "emails", "default"}, strict.queueOrder())
+	}
+
+	weighted := NewPool(&mockConsumer{}, &mockHistory{}, &mockMetrics{}, 1,
+		WithQueues(Queue{Name: "critical", Weight: 9}, Queue{Name: "reports", Weight: 1}))
+	first := make(map[string]int)
+	for range 1000 {
+		order := weighted.queueOrder()
+		assert.ElementsMatch(t, []string{"critical", "reports"}, order)
+		first[order[0]]++
+	}
+	// Очередь с меньшим весом иногда опрашивается первой, но заметно реже
+	assert.Greater(t, first["reports"], 0)
+	assert.Greater(t, first["critical"], first["reports"])
+}
+
 func TestPool_Register_ThreadSafety(t *testing.T) {
 	mm := &mockMetrics{}
 	pool := NewPool(&mockConsumer{}, &mockHistory{}, mm, 1)
-	h := func(ctx context.Context, t *model.Task) (string, error) { return "", nil }
+	h := func(ctx context.Context, t *model.Task) (any, error) { return "", nil }
 
 	go pool.Register("t1", h)
 	go pool.Register("t2", h)
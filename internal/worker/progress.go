@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+// progressInterval — не чаще этого интервала прогресс задачи записывается в
+// очередь. Промежуточные значения схлопываются, последнее записывается всегда.
+const progressInterval = time.Second
+
+type progressKey struct{}
+
+// ReportProgress сообщает ход выполнения задачи из обработчика: процент от 0
+// до 100 и необязательное сообщение. Вне обработчика пула ничего не делает.
+func ReportProgress(ctx context.Context, percent float64, message string) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("progress percent must be between 0 and 100, got %v", percent)
+	}
+
+	r, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok {
+		return nil
+	}
+	return r.report(model.Progress{Percent: percent, Message: message, UpdatedAt: time.Now()})
+}
+
+// closeProgress записывает отложенный прогресс задачи и отключает отчеты. Пул
+// вызывает ее до итогового статуса, иначе событие progress со статусом
+// processing пришло бы после completed или failed.
+func closeProgress(ctx context.Context) {
+	if r, ok := ctx.Value(progressKey{}).(*progressReporter); ok {
+		r.close()
+	}
+}
+
+type progressReporter struct {
+	write    func(model.Progress) error
+	interval time.Duration
+	log      *slog.Logger
+
+	mu      sync.Mutex
+	last    time.Time
+	pending *model.Progress
+	timer   *time.Timer
+	closed  bool
+}
+
+func (p *Pool) newProgressReporter(t *model.Task) *progressReporter {
+	// Отложенная запись идет из таймера, поэтому берем копию задачи, а не
+	// указатель, который пул меняет по ходу обработки
+	task := *t
+	task.Status = model.StatusProcessing
+
+	return &progressReporter{
+		write: func(pr model.Progress) error {
+			return p.queue.SetProgress(context.WithoutCancel(p.ctx), &task, pr)
+		},
+		interval: progressInterval,
+		log:      p.logger.With("task_id", t.ID),
+	}
+}
+
+// report записывает прогресс сразу, если с прошлой записи прошел interval, а
+// иначе откладывает запись до его истечения.
+func (r *progressReporter) report(pr model.Progress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	if wait := r.interval - time.Since(r.last); wait > 0 {
+		r.pending = &pr
+		if r.timer == nil {
+			r.timer = time.AfterFunc(wait, r.flush)
+		}
+		return nil
+	}
+
+	r.last = time.Now()
+	return r.write(pr)
+}
+
+func (r *progressReporter) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timer = nil
+	r.writePending()
+}
+
+// close записывает отложенное значение и отключает дальнейшие отчеты.
+func (r *progressReporter) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.writePending()
+	r.closed = true
+}
+
+func (r *progressReporter) writePending() {
+	if r.pending == nil || r.closed {
+		return
+	}
+
+	pr := *r.pending
+	r.pending = nil
+	r.last = time.Now()
+	if err := r.write(pr); err != nil {
+		r.log.Error("Failed to save task progress", "error", err)
+	}
+}
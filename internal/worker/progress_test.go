@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/podushkina/taskqueue/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type progressLog struct {
+	mu      sync.Mutex
+	percent []float64
+}
+
+func (l *progressLog) write(p model.Progress) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.percent = append(l.percent, p.Percent)
+	return nil
+}
+
+func (l *progressLog) values() []float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]float64(nil), l.percent...)
+}
+
+func TestProgressReporter_Throttle(t *testing.T) {
+	l := &progressLog{}
+	r := &progressReporter{write: l.write, interval: 50 * time.Millisecond}
+
+	require.NoError(t, r.report(model.Progress{Percent: 10}))
+	require.NoError(t, r.report(model.Progress{Percent: 20}))
+	require.NoError(t, r.report(model.Progress{Percent: 30}))
+	assert.Equal(t, []float64{10}, l.values())
+
+	// Промежуточное значение схлопнулось, последнее записано по таймеру
+	assert.Eventually(t, func() bool {
+		return len(l.values()) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []float64{10, 30}, l.values())
+
+	r.close()
+	assert.Equal(t, []float64{10, 30}, l.values())
+}
+
+func TestProgressReporter_CloseFlushesPending(t *testing.T) {
+	l := &progressLog{}
+	r := &progressReporter{write: l.write, interval: time.Hour}
+
+	require.NoError(t, r.report(model.Progress{Percent: 10}))
+	require.NoError(t, r.report(model.Progress{Percent: 100}))
+	r.close()
+
+	assert.Equal(t, []float64{10, 100}, l.values())
+
+	require.NoError(t, r.report(model.Progress{Percent: 50}))
+	assert.Equal(t, []float64{10, 100}, l.values())
+}
+
+func TestReportProgress(t *testing.T) {
+	assert.Error(t, ReportProgress(context.Background(), 101, ""))
+	assert.Error(t, ReportProgress(context.Background(), -1, ""))
+	// Вне пула прогресс просто игнорируется
+	assert.NoError(t, ReportProgress(context.Background(), 50, "half"))
+}
+
+func TestPool_Run_ReportsProgress(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	defer pool.cancel()
+
+	pool.Register("long", func(ctx context.Context, t *model.Task) (any, error) {
+		if err := ReportProgress(ctx, 40, "indexing"); err != nil {
+			return nil, err
+		}
+		return nil, ReportProgress(ctx, 90, "almost done")
+	})
+
+	pool.run(1, &model.Task{ID: "1", Type: "long", CreatedAt: time.Now()})
+
+	require.Len(t, mc.progress, 2)
+	assert.Equal(t, 40.0, mc.progress[0].Percent)
+	assert.Equal(t, "indexing", mc.progress[0].Message)
+	assert.Equal(t, 90.0, mc.progress[1].Percent)
+	assert.True(t, mc.ackCalled)
+	assert.False(t, mc.progressAfterFinish)
+}
+
+func TestPool_Run_FlushesProgressBeforeFailure(t *testing.T) {
+	mc := &mockConsumer{}
+	pool := NewPool(mc, &mockHistory{}, &mockMetrics{}, 1)
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	defer pool.cancel()
+
+	pool.Register("crash", func(ctx context.Context, t *model.Task) (any, error) {
+		_ = ReportProgress(ctx, 10, "")
+		_ = ReportProgress(ctx, 20, "")
+		panic("boom")
+	})
+
+	pool.run(1, &model.Task{ID: "1", Type: "crash", CreatedAt: time.Now()})
+
+	// Отложенное значение записано до перевода задачи в failed
+	require.Len(t, mc.progress, 2)
+	assert.Equal(t, 20.0, mc.progress[1].Percent)
+	assert.True(t, mc.deadLetter)
+	assert.False(t, mc.progressAfterFinish)
+}
@@ -0,0 +1,66 @@
+package worker
+
+import "github.com/podushkina/taskqueue/internal/model"
+
+// Queue — именованная очередь, которую обслуживает пул, и ее вес при
+// взвешенном опросе.
+type Queue struct {
+	Name   string
+	Weight int
+}
+
+var defaultQueues = []Queue{{Name: model.DefaultQueue, Weight: 1}}
+
+type PoolOption func(*Pool)
+
+// WithQueues задает очереди пула со взвешенным опросом: на каждом Pop порядок
+// очередей выбирается случайно пропорционально весам, поэтому очередь с
+// меньшим весом тоже не голодает. По умолчанию пул обслуживает только
+// model.DefaultQueue.
+func WithQueues(queues ...Queue) PoolOption {
+	return func(p *Pool) {
+		if len(queues) > 0 {
+			p.queues = queues
+			p.strictQueues = false
+		}
+	}
+}
+
+// WithStrictQueues задает очереди пула в строгом порядке: задачи следующей
+// очереди берутся, только когда предыдущие пусты.
+func WithStrictQueues(names ...string) PoolOption {
+	return func(p *Pool) {
+		if len(names) == 0 {
+			return
+		}
+		p.queues = make([]Queue, len(names))
+		for i, name := range names {
+			p.queues[i] = Queue{Name: name, Weight: 1}
+		}
+		p.strictQueues = true
+	}
+}
+
+// Queues возвращает имена очередей, которые обслуживает пул.
+func (p *Pool) Queues() []string {
+	names := make([]string, len(p.queues))
+	for i, q := range p.queues {
+		names[i] = q.Name
+	}
+	return names
+}
+
+// queueOrder возвращает порядок опроса очередей: как задано для строгого
+// режима или взвешенную случайную выборку без возвращения.
+func (p *Pool) queueOrder() []string {
+	if p.strictQueues || len(p.queues) == 1 {
+		return p.Queues()
+	}
+
+	order := model.WeightedOrder(p.queues, func(q Queue) int { return q.Weight })
+	names := make([]string, len(order))
+	for i, q := range order {
+		names[i] = q.Name
+	}
+	return names
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/podushkina/taskqueue/internal/model"
+)
+
+// TypedHandler получает уже разобранный payload и возвращает результат,
+// который пул сериализует в JSON.
+type TypedHandler[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// RegisterTyped регистрирует обработчик с типизированным payload. Ошибка разбора
+// payload считается постоянной: задача сразу попадает в DLQ.
+func RegisterTyped[In, Out any](p *Pool, taskType string, h TypedHandler[In, Out], opts ...HandlerOption) {
+	p.Register(taskType, func(ctx context.Context, t *model.Task) (any, error) {
+		var in In
+		if len(t.Payload) > 0 {
+			if err := t.DecodePayload(&in); err != nil {
+				return nil, Permanent(fmt.Errorf("invalid payload: %w", err))
+			}
+		}
+		return h(ctx, in)
+	}, opts...)
+}
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS queue VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- +goose Down
+ALTER TABLE schedules DROP COLUMN IF EXISTS queue;